@@ -4,7 +4,79 @@
 
 package goiaf
 
+import (
+	"net/url"
+	"strconv"
+)
+
+const (
+	// MinPageSize is the smallest page size accepted by the api.
+	MinPageSize int = 1
+
+	// MaxPageSize is the largest page size accepted by the api.
+	MaxPageSize int = 50
+
+	// DefaultPageSize is the page size used by new requests.
+	DefaultPageSize int = 10
+)
+
+// validator is implemented by requests which can be invalid, e.g. because
+// of a page size the api does not accept. Requests are validated when they
+// are sent, so only the final values of a request count.
+type validator interface {
+	validate() error
+}
+
 type request struct {
-	limit int
-	page  *int
+	page     *int
+	pageSize *int
+}
+
+func newRequest() request {
+	pageSize := DefaultPageSize
+	return request{pageSize: &pageSize}
+}
+
+func (r *request) setPage(value int) {
+	r.page = &value
+}
+
+func (r *request) setPageSize(value int) {
+	r.pageSize = &value
+}
+
+func (r request) validate() error {
+	if r.page != nil && *r.page < 1 {
+		return ErrInvalidPage
+	}
+	if r.pageSize != nil && (*r.pageSize < MinPageSize || *r.pageSize > MaxPageSize) {
+		return ErrInvalidPageSize
+	}
+
+	return nil
+}
+
+// params returns the pagination parameters of the request.
+func (r request) params() url.Values {
+	params := url.Values{}
+
+	if r.page != nil {
+		params.Set("page", strconv.Itoa(*r.page))
+	}
+	if r.pageSize != nil {
+		params.Set("pageSize", strconv.Itoa(*r.pageSize))
+	}
+
+	return params
+}
+
+// pageOf returns the page a request asks for. The api returns the first
+// page if no page is given.
+func pageOf(converter ParamConverter) int {
+	page, err := strconv.Atoi(converter.Convert().Get("page"))
+	if err != nil {
+		return 1
+	}
+
+	return page
 }
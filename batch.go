@@ -0,0 +1,202 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BatchError is returned by the batch methods if one or more resources could
+// not be fetched. It maps the id of every failed resource to its error.
+//
+// BatchError can be compared with errors.Is, which matches if the error of
+// any of the ids matches, e.g. ErrResourceNotFound.
+type BatchError map[int]error
+
+// Error makes the BatchError type implement the error interface.
+func (e BatchError) Error() string {
+	ids := make([]int, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	if len(ids) == 1 {
+		return fmt.Sprintf("Failed to fetch id %d: %v", ids[0], e[ids[0]])
+	}
+
+	return fmt.Sprintf("Failed to fetch %d ids, first id %d: %v", len(ids), ids[0], e[ids[0]])
+}
+
+// Unwrap returns the errors of all failed ids.
+func (e BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// batchError collects the errors of a batch from several goroutines.
+type batchError struct {
+	mu   sync.Mutex
+	errs BatchError
+}
+
+func (be *batchError) add(id int, err error) {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	be.errs[id] = err
+}
+
+// err returns the collected errors, or nil if there are none.
+func (be *batchError) err() error {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	if len(be.errs) == 0 {
+		return nil
+	}
+
+	return be.errs
+}
+
+// uniqueIDs returns the ids without duplicates, keeping the order of
+// their first occurrence.
+func uniqueIDs[T comparable](ids []T) []T {
+	seen := map[T]bool{}
+	unique := []T{}
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
+
+// BooksByID fetches the books with the given ids concurrently, using at most the
+// given number of workers. Duplicate ids are fetched once. The books that
+// could be fetched are returned in the order of the ids, together with a
+// BatchError holding the error for every id that failed, so a single missing
+// book does not fail the whole batch.
+//
+// If the context is cancelled, the books fetched so far are returned
+// together with the context error. A workers value of zero or less uses
+// DefaultWorkers.
+func BooksByID(ctx context.Context, client Client, ids []BookID, workers int) ([]Book, error) {
+	ids = uniqueIDs(ids)
+	results := make([]*Book, len(ids))
+	batchErr := batchError{errs: BatchError{}}
+
+	err := parallel(ctx, len(ids), workers, func(ctx context.Context, i int) error {
+		book, err := client.BookContext(ctx, int(ids[i]))
+		if err != nil {
+			batchErr.add(int(ids[i]), err)
+			return nil
+		}
+
+		results[i] = &book
+		return nil
+	})
+
+	books := []Book{}
+	for _, result := range results {
+		if result != nil {
+			books = append(books, *result)
+		}
+	}
+
+	if err != nil {
+		return books, err
+	}
+
+	return books, batchErr.err()
+}
+
+// CharactersByID fetches the characters with the given ids concurrently, using at most the
+// given number of workers. Duplicate ids are fetched once. The characters that
+// could be fetched are returned in the order of the ids, together with a
+// BatchError holding the error for every id that failed, so a single missing
+// character does not fail the whole batch.
+//
+// If the context is cancelled, the characters fetched so far are returned
+// together with the context error. A workers value of zero or less uses
+// DefaultWorkers.
+func CharactersByID(ctx context.Context, client Client, ids []CharacterID, workers int) ([]Character, error) {
+	ids = uniqueIDs(ids)
+	results := make([]*Character, len(ids))
+	batchErr := batchError{errs: BatchError{}}
+
+	err := parallel(ctx, len(ids), workers, func(ctx context.Context, i int) error {
+		character, err := client.CharacterContext(ctx, int(ids[i]))
+		if err != nil {
+			batchErr.add(int(ids[i]), err)
+			return nil
+		}
+
+		results[i] = &character
+		return nil
+	})
+
+	characters := []Character{}
+	for _, result := range results {
+		if result != nil {
+			characters = append(characters, *result)
+		}
+	}
+
+	if err != nil {
+		return characters, err
+	}
+
+	return characters, batchErr.err()
+}
+
+// HousesByID fetches the houses with the given ids concurrently, using at most the
+// given number of workers. Duplicate ids are fetched once. The houses that
+// could be fetched are returned in the order of the ids, together with a
+// BatchError holding the error for every id that failed, so a single missing
+// house does not fail the whole batch.
+//
+// If the context is cancelled, the houses fetched so far are returned
+// together with the context error. A workers value of zero or less uses
+// DefaultWorkers.
+func HousesByID(ctx context.Context, client Client, ids []HouseID, workers int) ([]House, error) {
+	ids = uniqueIDs(ids)
+	results := make([]*House, len(ids))
+	batchErr := batchError{errs: BatchError{}}
+
+	err := parallel(ctx, len(ids), workers, func(ctx context.Context, i int) error {
+		house, err := client.HouseContext(ctx, int(ids[i]))
+		if err != nil {
+			batchErr.add(int(ids[i]), err)
+			return nil
+		}
+
+		results[i] = &house
+		return nil
+	})
+
+	houses := []House{}
+	for _, result := range results {
+		if result != nil {
+			houses = append(houses, *result)
+		}
+	}
+
+	if err != nil {
+		return houses, err
+	}
+
+	return houses, batchErr.err()
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+func characterNames(characters []goiaf.Character) []string {
+	names := []string{}
+	for _, character := range characters {
+		names = append(names, character.Name)
+	}
+
+	return names
+}
+
+func TestCharactersByID(t *testing.T) {
+	server := newTestServer(t)
+
+	ids := []goiaf.CharacterID{7, 3, 7, 12, 3, 1}
+	characters, err := goiaf.CharactersByID(context.Background(), server.Client(), ids, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Character 7", "Character 3", "Character 12", "Character 1"}
+	if got := characterNames(characters); !slices.Equal(got, want) {
+		t.Errorf("CharactersByID returned %q, want %q", got, want)
+	}
+	if server.Requests() != 4 {
+		t.Errorf("server received %d requests, want duplicate ids fetched once", server.Requests())
+	}
+}
+
+func TestCharactersByIDPartial(t *testing.T) {
+	server := newTestServer(t)
+
+	ids := []goiaf.CharacterID{2, 100, 4, 200, 100}
+	characters, err := goiaf.CharactersByID(context.Background(), server.Client(), ids, 3)
+
+	want := []string{"Character 2", "Character 4"}
+	if got := characterNames(characters); !slices.Equal(got, want) {
+		t.Errorf("CharactersByID returned %q, want %q", got, want)
+	}
+
+	if !errors.Is(err, goiaf.ErrResourceNotFound) {
+		t.Errorf("CharactersByID returned %v, want an error matching ErrResourceNotFound", err)
+	}
+
+	var batchErr goiaf.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("CharactersByID returned %T, want a BatchError", err)
+	}
+	if len(batchErr) != 2 || batchErr[100] == nil || batchErr[200] == nil {
+		t.Errorf("BatchError = %v, want errors for ids 100 and 200", batchErr)
+	}
+
+	var apiErr *goiaf.APIError
+	if !errors.As(batchErr[100], &apiErr) || apiErr.StatusCode != 404 {
+		t.Errorf("error for id 100 = %v, want an APIError with status 404", batchErr[100])
+	}
+}
+
+func TestBooksByIDOffline(t *testing.T) {
+	fixtures := testFixtures()
+	client := goiaf.NewOfflineClient(fixtures.Books, fixtures.Characters, fixtures.Houses)
+
+	books, err := goiaf.BooksByID(context.Background(), client, []goiaf.BookID{3, 9, 1, 3}, 0)
+	if len(books) != 2 || books[0].Name != "Book 3" || books[1].Name != "Book 1" {
+		t.Errorf("BooksByID returned %v, want Book 3 and Book 1", books)
+	}
+	if !errors.Is(err, goiaf.ErrResourceNotFound) {
+		t.Errorf("BooksByID returned %v, want an error matching ErrResourceNotFound", err)
+	}
+	if err == nil || err.Error() != "Failed to fetch id 9: GET "+fixtureBaseURL+"/books/9: 404 Not Found" {
+		t.Errorf("BooksByID error message = %v", err)
+	}
+}
+
+func TestHousesByIDCancelled(t *testing.T) {
+	fixtures := testFixtures()
+	client := goiaf.NewOfflineClient(fixtures.Books, fixtures.Characters, fixtures.Houses)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	houses, err := goiaf.HousesByID(ctx, client, []goiaf.HouseID{1, 2}, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("HousesByID returned %v, want context.Canceled", err)
+	}
+	if len(houses) != 0 {
+		t.Errorf("HousesByID returned %d houses from a cancelled context", len(houses))
+	}
+}
+
+func TestBatchErrorMessage(t *testing.T) {
+	err := goiaf.BatchError{
+		9: goiaf.ErrServerError,
+		4: goiaf.ErrResourceNotFound,
+	}
+
+	if want := "Failed to fetch 2 ids, first id 4: Resource not found"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if !errors.Is(err, goiaf.ErrServerError) || errors.Is(err, goiaf.ErrRateLimited) {
+		t.Errorf("errors.Is does not match the errors of the ids")
+	}
+}
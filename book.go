@@ -12,39 +12,39 @@ import (
 // Book represents the book resources that is returned from the api.
 type Book struct {
 	// The hypermedia URL of this resource.
-	URL string
+	URL string `json:"url"`
 
 	// The name of this book.
-	Name string
+	Name string `json:"name"`
 
 	// The International Standard Book Number that uniquely identifies this book.
 	// The format used is ISBN-13.
-	ISBN string
+	ISBN string `json:"isbn"`
 
 	// An array of names of the authors that wrote this book.
-	Authors []string
+	Authors []string `json:"authors"`
 
 	// The number of pages in this book.
-	NumberOfPages int
+	NumberOfPages int `json:"numberOfPages"`
 
 	// The company that published this book.
-	Publisher string
+	Publisher string `json:"publisher"`
 
 	// The country which this book was published in.
-	Country string
+	Country string `json:"country"`
 
 	// The type of media this book was released in. Possible values are: Hardback,
 	// Hardcover, GraphicNovel and Paperback.
-	MediaType string
+	MediaType string `json:"mediaType"`
 
 	// The date, in ISO 8601 format, which this book was released.
-	Released time.Time
+	Released time.Time `json:"released"`
 
 	// An array of Character ids that has been in this book.
-	CharacterIds []int
+	CharacterIds []CharacterID `json:"characterIds"`
 
 	// An array of Character ids that has had a POV-chapter in this book.
-	PovCharacterIds []int
+	PovCharacterIds []CharacterID `json:"povCharacterIds"`
 }
 
 type book struct {
@@ -72,20 +72,20 @@ func (b book) Convert() Book {
 		Country:         b.Country,
 		MediaType:       b.MediaType,
 		Released:        b.Released.Value(),
-		CharacterIds:    b.Characters.ids(),
-		PovCharacterIds: b.PovCharacters.ids(),
+		CharacterIds:    ids[CharacterID](b.Characters),
+		PovCharacterIds: ids[CharacterID](b.PovCharacters),
 	}
 
 	return book
 }
 
 type booksResponse struct {
-	links map[string]string
+	links Links
 
 	Books []book
 }
 
-func (booksResponse *booksResponse) Link(links map[string]string) {
+func (booksResponse *booksResponse) Link(links Links) {
 	booksResponse.links = links
 }
 
@@ -6,7 +6,6 @@ package goiaf
 
 import (
 	"net/url"
-	"strconv"
 	"time"
 )
 
@@ -14,7 +13,17 @@ import (
 type BookRequest interface {
 	ParamConverter
 
+	// Page sets the page of the result set to return. The first page is 1.
+	Page(int) BookRequest
+
+	// PageSize sets the maximum books to return per page. The api accepts
+	// a page size between MinPageSize and MaxPageSize, the default is
+	// DefaultPageSize.
+	PageSize(int) BookRequest
+
 	// Limit sets the maximum books to return.
+	//
+	// Deprecated: Limit is an alias for PageSize.
 	Limit(int) BookRequest
 
 	// Name can be used to filter the books by name.
@@ -33,9 +42,7 @@ type BookRequest interface {
 
 // NewBookRequest returns a new BookRequest which can be used to filter books.
 func NewBookRequest() BookRequest {
-	b := bookRequest{}
-	b.limit = 10
-	return b
+	return bookRequest{request: newRequest()}
 }
 
 type bookRequest struct {
@@ -46,11 +53,20 @@ type bookRequest struct {
 	toReleaseDate   *string
 }
 
-func (request bookRequest) Limit(value int) BookRequest {
-	request.limit = value
+func (request bookRequest) Page(value int) BookRequest {
+	request.setPage(value)
+	return request
+}
+
+func (request bookRequest) PageSize(value int) BookRequest {
+	request.setPageSize(value)
 	return request
 }
 
+func (request bookRequest) Limit(value int) BookRequest {
+	return request.PageSize(value)
+}
+
 func (request bookRequest) Name(value string) BookRequest {
 	request.name = &value
 	return request
@@ -69,12 +85,8 @@ func (request bookRequest) ToReleaseDate(value time.Time) BookRequest {
 }
 
 func (request bookRequest) Convert() url.Values {
-	params := url.Values{}
-	params.Add("page", strconv.Itoa(request.limit))
+	params := request.params()
 
-	if request.page != nil {
-		params.Set("pageSize", strconv.Itoa(*request.page))
-	}
 	if request.name != nil {
 		params.Set("name", *request.name)
 	}
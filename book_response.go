@@ -4,6 +4,8 @@
 
 package goiaf
 
+import "encoding/json"
+
 // BookResponse contains the data from the performed request.
 //
 // BookResponse supports pagination by having four methods: Next(), Prev(), First() and Last().
@@ -11,35 +13,62 @@ package goiaf
 // the request will return a different result set.
 //
 // Note that, if a result set is not available these methods will return the ErrNoResultSet error.
+//
+// BookResponse can be marshaled to and unmarshaled from JSON, the pagination links are kept.
 type BookResponse struct {
 	// Data contains the books from the request.
 	Data []Book
 
-	links map[string]string
+	links Links
+}
+
+type bookResponseJSON struct {
+	Data  []Book `json:"data"`
+	Links Links  `json:"links"`
+}
+
+// MarshalJSON makes the BookResponse type implement the json.Marshaler interface.
+func (response BookResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bookResponseJSON{
+		Data:  response.Data,
+		Links: response.links,
+	})
+}
+
+// UnmarshalJSON makes the BookResponse type implement the json.Unmarshaler interface.
+func (response *BookResponse) UnmarshalJSON(data []byte) error {
+	r := bookResponseJSON{}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	response.Data = r.Data
+	response.links = r.Links
+	return nil
 }
 
 // Next returns a BookRequest, which can be used to retrieve
 // the next result set of books.
 func (response BookResponse) Next() (BookRequest, error) {
-	return response.getRequestForURL(response.links["next"])
+	return response.getRequestForURL(response.links.URL("next"))
 }
 
 // Prev returns a BookRequest, which can be used to retrieve
 // the previous result set of books.
 func (response BookResponse) Prev() (BookRequest, error) {
-	return response.getRequestForURL(response.links["prev"])
+	return response.getRequestForURL(response.links.URL("prev"))
 }
 
 // First returns a BookRequest, which can be used to retrieve
 // the last result set of books.
 func (response BookResponse) First() (BookRequest, error) {
-	return response.getRequestForURL(response.links["first"])
+	return response.getRequestForURL(response.links.URL("first"))
 }
 
 // Last returns a BookRequest, which can be used to retrieve
 // the first result set of books.
 func (response BookResponse) Last() (BookRequest, error) {
-	return response.getRequestForURL(response.links["last"])
+	return response.getRequestForURL(response.links.URL("last"))
 }
 
 func (response BookResponse) getRequestForURL(urlStr string) (BookRequest, error) {
@@ -54,8 +83,8 @@ func (response BookResponse) getRequestForURL(urlStr string) (BookRequest, error
 	}
 
 	request := bookRequest{}
-	request.limit = page
-	request.page = &pageSize
+	request.setPage(page)
+	request.setPageSize(pageSize)
 
 	if value := query.Get("name"); value != "" {
 		request.name = &value
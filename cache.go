@@ -0,0 +1,221 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheSize int = 1000
+)
+
+// Cache is a store for responses from the api, keyed by the request URL.
+//
+// The client uses the cache for all requests, both for single resources and
+// for result sets. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for the key, and whether it was found.
+	Get(key string) ([]byte, bool)
+
+	// Set stores the value for the key. The value expires after the given
+	// ttl, a ttl of zero means that the value does not expire.
+	Set(key string, value []byte, ttl time.Duration)
+
+	// Delete removes the value for the key, if any.
+	Delete(key string)
+}
+
+// WithCache enables caching of responses in the given cache.
+//
+// Responses are cached based on the cache headers sent by the api, see
+// WithHTTPCache. If the api does not send a max-age for a response, it is
+// served from the cache without a request for the given ttl. A ttl of zero
+// means that such responses are always revalidated.
+func WithCache(cache Cache, ttl time.Duration) Option {
+	return func(c *client) {
+		c.httpCache = &httpCache{cache: cache, ttl: ttl}
+	}
+}
+
+// LRUCache is an in-memory Cache which holds a bounded number of entries.
+// When the cache is full, the least recently used entry is evicted.
+type LRUCache struct {
+	mu sync.Mutex
+
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type lruItem struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache returns an LRUCache which holds at most maxEntries entries.
+// A maxEntries of zero or less means that the number of entries is not bounded.
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+// Get makes the LRUCache type implement the Cache interface.
+func (lc *LRUCache) Get(key string) ([]byte, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	elem, ok := lc.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := elem.Value.(*lruItem)
+	if !item.expires.IsZero() && time.Now().After(item.expires) {
+		lc.removeElement(elem)
+		return nil, false
+	}
+
+	lc.ll.MoveToFront(elem)
+	return item.value, true
+}
+
+// Set makes the LRUCache type implement the Cache interface.
+func (lc *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	item := &lruItem{key: key, value: value}
+	if ttl > 0 {
+		item.expires = time.Now().Add(ttl)
+	}
+
+	if elem, ok := lc.items[key]; ok {
+		elem.Value = item
+		lc.ll.MoveToFront(elem)
+		return
+	}
+
+	lc.items[key] = lc.ll.PushFront(item)
+	if lc.maxEntries > 0 && lc.ll.Len() > lc.maxEntries {
+		lc.removeElement(lc.ll.Back())
+	}
+}
+
+// Delete makes the LRUCache type implement the Cache interface.
+func (lc *LRUCache) Delete(key string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if elem, ok := lc.items[key]; ok {
+		lc.removeElement(elem)
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (lc *LRUCache) Len() int {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	return lc.ll.Len()
+}
+
+func (lc *LRUCache) removeElement(elem *list.Element) {
+	lc.ll.Remove(elem)
+	delete(lc.items, elem.Value.(*lruItem).key)
+}
+
+// FileCache is a Cache which stores every entry as a file in a directory,
+// so the cached responses survive restarts of the process.
+type FileCache struct {
+	dir string
+}
+
+type fileCacheItem struct {
+	Expires time.Time `json:"expires"`
+	Value   []byte    `json:"value"`
+}
+
+// NewFileCache returns a FileCache which stores its entries in the given
+// directory. The directory is created if it does not exist.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &FileCache{dir: dir}, nil
+}
+
+// Get makes the FileCache type implement the Cache interface.
+func (fc *FileCache) Get(key string) ([]byte, bool) {
+	b, err := os.ReadFile(fc.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	item := fileCacheItem{}
+	if err := json.Unmarshal(b, &item); err != nil {
+		return nil, false
+	}
+	if !item.Expires.IsZero() && time.Now().After(item.Expires) {
+		fc.Delete(key)
+		return nil, false
+	}
+
+	return item.Value, true
+}
+
+// Set makes the FileCache type implement the Cache interface. Errors while
+// writing the entry are ignored, the entry is then simply not cached.
+func (fc *FileCache) Set(key string, value []byte, ttl time.Duration) {
+	item := fileCacheItem{Value: value}
+	if ttl > 0 {
+		item.Expires = time.Now().Add(ttl)
+	}
+
+	b, err := json.Marshal(item)
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first, so a concurrent Get never reads
+	// a partially written entry.
+	tmp, err := os.CreateTemp(fc.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), fc.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete makes the FileCache type implement the Cache interface.
+func (fc *FileCache) Delete(key string) {
+	os.Remove(fc.path(key))
+}
+
+func (fc *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(fc.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEviction(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), 0)
+	cache.Set("b", []byte("2"), 0)
+
+	// Reading a makes b the least recently used entry.
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("a is missing before the cache is full")
+	}
+	cache.Set("c", []byte("3"), 0)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b was not evicted as the least recently used entry")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("%s was evicted, want b evicted", key)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("cache holds %d entries, want 2", cache.Len())
+	}
+}
+
+func TestLRUCacheUpdate(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), 0)
+	cache.Set("b", []byte("2"), 0)
+	cache.Set("a", []byte("3"), 0)
+	cache.Set("c", []byte("4"), 0)
+
+	if value, ok := cache.Get("a"); !ok || string(value) != "3" {
+		t.Errorf("Get(a) = %q, %v, want updated value 3", value, ok)
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b was not evicted after a was updated")
+	}
+}
+
+func TestLRUCacheUnbounded(t *testing.T) {
+	cache := NewLRUCache(0)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		cache.Set(key, []byte(key), 0)
+	}
+
+	if cache.Len() != 4 {
+		t.Errorf("cache holds %d entries, want 4", cache.Len())
+	}
+}
+
+func TestLRUCacheExpiry(t *testing.T) {
+	cache := NewLRUCache(10)
+	cache.Set("short", []byte("1"), time.Millisecond)
+	cache.Set("forever", []byte("2"), 0)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Error("expired entry was returned")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("cache holds %d entries, want the expired entry removed", cache.Len())
+	}
+	if _, ok := cache.Get("forever"); !ok {
+		t.Error("entry without a ttl expired")
+	}
+}
+
+func TestLRUCacheDelete(t *testing.T) {
+	cache := NewLRUCache(10)
+	cache.Set("a", []byte("1"), 0)
+	cache.Delete("a")
+	cache.Delete("missing")
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("deleted entry was returned")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("cache holds %d entries, want none", cache.Len())
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "http://www.anapioficeandfire.com/api/characters/583"
+	cache.Set(key, []byte("Jon Snow"), 0)
+	cache.Set("short", []byte("1"), time.Millisecond)
+
+	// A new cache on the same directory sees the entries of the first one.
+	reopened, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := reopened.Get(key); !ok || string(value) != "Jon Snow" {
+		t.Errorf("Get(%q) = %q, %v, want Jon Snow from the reopened cache", key, value, ok)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := reopened.Get("short"); ok {
+		t.Error("expired entry was returned")
+	}
+
+	reopened.Delete(key)
+	if _, ok := cache.Get(key); ok {
+		t.Error("deleted entry was returned")
+	}
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("missing entry was returned")
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// CassetteEnv is the environment variable which, if set, makes NewClient
+	// record or replay its traffic using the cassette file it names, unless
+	// a cassette is configured with WithCassette.
+	CassetteEnv string = "GOIAF_CASSETTE"
+
+	// RecordModeEnv is the environment variable which selects the mode used
+	// together with CassetteEnv. Possible values are record and replay,
+	// the default is replay.
+	RecordModeEnv string = "GOIAF_RECORD_MODE"
+)
+
+// ErrInteractionNotFound will be used if a request is replayed from a cassette
+// which does not contain a matching interaction.
+var ErrInteractionNotFound = errors.New("No matching interaction in cassette")
+
+// RecordMode selects whether a Recorder records or replays traffic.
+type RecordMode int
+
+const (
+	// ModeReplay serves every request from the cassette, without sending
+	// it. Requests which are not in the cassette fail.
+	ModeReplay RecordMode = iota
+
+	// ModeRecord sends every request and writes it together with its
+	// response to the cassette.
+	ModeRecord
+)
+
+// ParseRecordMode returns the RecordMode for the given name, which is
+// either record or replay.
+func ParseRecordMode(name string) (RecordMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	}
+
+	return ModeReplay, fmt.Errorf("Invalid record mode %q", name)
+}
+
+// WithCassette records the traffic of the client to the cassette file at path,
+// or replays it from there, depending on the mode. If the cassette cannot be
+// opened, every request of the client fails with that error. The cassette
+// wraps the transport of the client, regardless of the order of the options.
+func WithCassette(path string, mode RecordMode) Option {
+	return func(c *client) {
+		c.cassette = &cassetteConfig{path: path, mode: mode}
+	}
+}
+
+// cassetteConfig is the cassette of a client, set by WithCassette or the
+// environment.
+type cassetteConfig struct {
+	path string
+	mode RecordMode
+	err  error
+}
+
+// cassetteFromEnv returns the cassette configured by the environment, if any.
+func cassetteFromEnv() *cassetteConfig {
+	path := os.Getenv(CassetteEnv)
+	if path == "" {
+		return nil
+	}
+
+	mode, err := ParseRecordMode(os.Getenv(RecordModeEnv))
+	return &cassetteConfig{path: path, mode: mode, err: err}
+}
+
+// transport wraps the transport with a Recorder for the cassette.
+func (c *cassetteConfig) transport(transport http.RoundTripper) http.RoundTripper {
+	if c.err != nil {
+		return errorTransport{c.err}
+	}
+
+	recorder, err := NewRecorder(c.path, c.mode, transport)
+	if err != nil {
+		return errorTransport{err}
+	}
+
+	return recorder
+}
+
+// errorTransport fails every request with the same error.
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// Recorder is an http.RoundTripper which records requests and their responses,
+// including the link header used for pagination, to a cassette file, and
+// replays them from there.
+//
+// Requests are matched by method and URL. If the same request was recorded
+// several times, the responses are replayed in the recorded order, and the
+// last one is repeated after that. Recording a request again replaces the
+// interactions recorded for it by earlier recorders.
+type Recorder struct {
+	path      string
+	mode      RecordMode
+	transport http.RoundTripper
+
+	// The lock of the cassette file, shared by all recorders of the file.
+	file *sync.Mutex
+
+	mu       sync.Mutex
+	cassette cassette
+	replayed map[string]int
+
+	// recorded holds the requests recorded by this recorder, whose earlier
+	// interactions have been replaced.
+	recorded map[string]bool
+}
+
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// cassetteLocks holds a mutex per cassette file, so recorders of several
+// clients can append to the same cassette.
+var cassetteLocks sync.Map
+
+func cassetteLock(path string) *sync.Mutex {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	lock, _ := cassetteLocks.LoadOrStore(path, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// NewRecorder returns a Recorder for the cassette at path. In ModeRecord the
+// requests are sent with the given transport, or http.DefaultTransport if it
+// is nil, and appended to the cassette, which is created if it does not exist.
+// In ModeReplay the cassette must exist.
+func NewRecorder(path string, mode RecordMode, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+		file:      cassetteLock(path),
+		replayed:  map[string]int{},
+		recorded:  map[string]bool{},
+	}
+
+	r.file.Lock()
+	defer r.file.Unlock()
+
+	c, err := readCassette(path)
+	if mode == ModeRecord && os.IsNotExist(err) {
+		return r, writeCassette(path, cassette{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.cassette = c
+
+	return r, nil
+}
+
+// RoundTrip makes the Recorder type implement the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeRecord {
+		return r.record(req)
+	}
+
+	return r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.append(interaction{
+		Request: recordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+		},
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       string(body),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := req.Method + " " + req.URL.String()
+
+	matches := []interaction{}
+	for _, i := range r.cassette.Interactions {
+		if i.Request.Method == req.Method && i.Request.URL == req.URL.String() {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInteractionNotFound, key)
+	}
+
+	n := r.replayed[key]
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	r.replayed[key]++
+
+	recorded := matches[n].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// append adds the interaction to the cassette file. The file is read again
+// first, so interactions recorded by other recorders of the file are kept.
+// The first time the recorder records a request, the interactions already
+// recorded for it are removed.
+func (r *Recorder) append(i interaction) error {
+	r.file.Lock()
+	defer r.file.Unlock()
+
+	c, err := readCassette(r.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	r.mu.Lock()
+	key := i.Request.Method + " " + i.Request.URL
+	if !r.recorded[key] {
+		c.Interactions = slices.DeleteFunc(c.Interactions, func(recorded interaction) bool {
+			return recorded.Request == i.Request
+		})
+		r.recorded[key] = true
+	}
+	r.mu.Unlock()
+
+	c.Interactions = append(c.Interactions, i)
+
+	return writeCassette(r.path, c)
+}
+
+func readCassette(path string) (cassette, error) {
+	var c cassette
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return c, nil
+}
+
+func writeCassette(path string, c cassette) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0644)
+}
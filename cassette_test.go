@@ -0,0 +1,160 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/goiaftest"
+)
+
+func TestCassetteRecordAppends(t *testing.T) {
+	server := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	t.Setenv(goiaf.CassetteEnv, path)
+	t.Setenv(goiaf.RecordModeEnv, "record")
+
+	first := server.Client()
+	if _, err := first.Character(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Creating another client must not erase what the first one recorded.
+	second := server.Client()
+	if _, err := second.Character(2); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(goiaf.RecordModeEnv, "replay")
+	requests := server.Requests()
+
+	replay := server.Client()
+	for _, id := range []int{1, 2} {
+		character, err := replay.Character(id)
+		if err != nil {
+			t.Fatalf("Character(%d) was not replayed: %v", id, err)
+		}
+		if character.Name == "" {
+			t.Errorf("Character(%d) was replayed without name", id)
+		}
+	}
+	if server.Requests() != requests {
+		t.Errorf("replay sent %d requests to the server", server.Requests()-requests)
+	}
+}
+
+// cassetteRequests returns the path and status of every interaction in the
+// cassette.
+func cassetteRequests(t *testing.T, path, baseURL string) []string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c struct {
+		Interactions []struct {
+			Request struct {
+				URL string `json:"url"`
+			} `json:"request"`
+			Response struct {
+				StatusCode int `json:"statusCode"`
+			} `json:"response"`
+		} `json:"interactions"`
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []string{}
+	for _, i := range c.Interactions {
+		requests = append(requests, fmt.Sprintf("%s %d", strings.TrimPrefix(i.Request.URL, baseURL), i.Response.StatusCode))
+	}
+
+	return requests
+}
+
+func TestCassetteRecordReplacesInteractions(t *testing.T) {
+	server := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	// The first recording fails and is retried, so both responses are
+	// recorded for the same request.
+	server.AddFault(goiaftest.Fault{StatusCode: http.StatusServiceUnavailable, Times: 1})
+	first := server.Client(goiaf.WithCassette(path, goiaf.ModeRecord), goiaf.WithRetryPolicy(fastRetries))
+	if _, err := first.Book(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.Book(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := cassetteRequests(t, path, server.BaseURL()), []string{"/books/1 503", "/books/1 200", "/books/2 200"}; !slices.Equal(got, want) {
+		t.Errorf("cassette holds %v, want %v", got, want)
+	}
+
+	// Recording Book(1) again replaces both earlier interactions, and keeps
+	// the one of Book(2).
+	second := server.Client(goiaf.WithCassette(path, goiaf.ModeRecord))
+	if _, err := second.Book(1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cassetteRequests(t, path, server.BaseURL()), []string{"/books/2 200", "/books/1 200"}; !slices.Equal(got, want) {
+		t.Errorf("cassette holds %v, want %v", got, want)
+	}
+
+	replay := server.Client(goiaf.WithCassette(path, goiaf.ModeReplay), goiaf.WithTransport(failingTransport{}))
+	if _, err := replay.Book(1); err != nil {
+		t.Errorf("Book(1) replayed the replaced failure: %v", err)
+	}
+}
+
+func TestCassetteWrapsTransport(t *testing.T) {
+	server := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder := server.Client(goiaf.WithCassette(path, goiaf.ModeRecord))
+	if _, err := recorder.Book(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// A transport set after the cassette must not replace it.
+	replay := server.Client(goiaf.WithCassette(path, goiaf.ModeReplay), goiaf.WithTransport(failingTransport{}))
+	book, err := replay.Book(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Name != "Book 1" {
+		t.Errorf("Book(1).Name = %q, want Book 1", book.Name)
+	}
+
+	_, err = replay.Book(2)
+	if !errors.Is(err, goiaf.ErrInteractionNotFound) {
+		t.Errorf("Book(2) returned %v, want ErrInteractionNotFound", err)
+	}
+}
+
+func TestCassetteFromEnvIgnoredWithOption(t *testing.T) {
+	server := newTestServer(t)
+	dir := t.TempDir()
+
+	t.Setenv(goiaf.CassetteEnv, filepath.Join(dir, "missing.json"))
+	t.Setenv(goiaf.RecordModeEnv, "replay")
+
+	client := server.Client(goiaf.WithCassette(filepath.Join(dir, "cassette.json"), goiaf.ModeRecord))
+	if _, err := client.House(1); err != nil {
+		t.Fatalf("House(1) returned %v, want the environment to be ignored", err)
+	}
+}
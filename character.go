@@ -9,55 +9,55 @@ import "encoding/json"
 // Character represent the character resource in the api
 type Character struct {
 	// The hypermedia URL of this resource.
-	URL string
+	URL string `json:"url"`
 
 	// The name of this character.
-	Name string
+	Name string `json:"name"`
 
 	// The gender of this character. Possible values are:
 	// Female, Male and Unknown.
-	Gender string
+	Gender string `json:"gender"`
 
 	// The culture that this character belongs to.
-	Culture string
+	Culture string `json:"culture"`
 
 	// The year that this person was born.
-	Born string
+	Born string `json:"born"`
 
 	// The year that this person died.
-	Died string
+	Died string `json:"died"`
 
 	// The titles that this character holds.
-	Titles []string
+	Titles []string `json:"titles"`
 
 	// The aliases that this character goes by.
-	Aliases []string
+	Aliases []string `json:"aliases"`
 
-	// The character id of this character's father.
-	FatherID int
+	// The character id of this character's father, or nil if unknown.
+	FatherID *CharacterID `json:"fatherId"`
 
-	// The character id of this character's mother.
-	MotherID int
+	// The character id of this character's mother, or nil if unknown.
+	MotherID *CharacterID `json:"motherId"`
 
-	// The character id of this character's spouse.
-	SpouseID int
+	// The character id of this character's spouse, or nil if unknown.
+	SpouseID *CharacterID `json:"spouseId"`
 
 	// An array of Houses ids that this character is loyal to.
-	AllegianceIds []int
+	AllegianceIds []HouseID `json:"allegianceIds"`
 
 	// An array of Book ids that this character has been in.
-	BookIds []int
+	BookIds []BookID `json:"bookIds"`
 
 	// An array of Book ids that this character has had a POV-chapter in.
-	PovBookIds []int
+	PovBookIds []BookID `json:"povBookIds"`
 
 	// An array of names of the seasons of Game of Thrones that this
 	// character has been in.
-	TvSeries []string
+	TvSeries []string `json:"tvSeries"`
 
 	// An array of actor names that has played this character in
 	// the TV show Game Of Thrones.
-	PlayedBy []string
+	PlayedBy []string `json:"playedBy"`
 }
 
 type character struct {
@@ -89,12 +89,12 @@ func (c character) Convert() Character {
 		Died:          c.Died,
 		Titles:        c.Titles,
 		Aliases:       c.Aliases,
-		FatherID:      c.Father.id(),
-		MotherID:      c.Mother.id(),
-		SpouseID:      c.Spouse.id(),
-		AllegianceIds: c.Allegiances.ids(),
-		BookIds:       c.Books.ids(),
-		PovBookIds:    c.PovBooks.ids(),
+		FatherID:      optionalID[CharacterID](c.Father),
+		MotherID:      optionalID[CharacterID](c.Mother),
+		SpouseID:      optionalID[CharacterID](c.Spouse),
+		AllegianceIds: ids[HouseID](c.Allegiances),
+		BookIds:       ids[BookID](c.Books),
+		PovBookIds:    ids[BookID](c.PovBooks),
 		TvSeries:      c.TvSeries,
 		PlayedBy:      c.PlayedBy,
 	}
@@ -103,12 +103,12 @@ func (c character) Convert() Character {
 }
 
 type charactersResponse struct {
-	links map[string]string
+	links Links
 
 	Characters []character
 }
 
-func (charactersResponse *charactersResponse) Link(links map[string]string) {
+func (charactersResponse *charactersResponse) Link(links Links) {
 	charactersResponse.links = links
 }
 
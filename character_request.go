@@ -13,7 +13,17 @@ import (
 type CharacterRequest interface {
 	ParamConverter
 
+	// Page sets the page of the result set to return. The first page is 1.
+	Page(int) CharacterRequest
+
+	// PageSize sets the maximum characters to return per page. The api accepts
+	// a page size between MinPageSize and MaxPageSize, the default is
+	// DefaultPageSize.
+	PageSize(int) CharacterRequest
+
 	// Limit sets the maximum characters to return.
+	//
+	// Deprecated: Limit is an alias for PageSize.
 	Limit(int) CharacterRequest
 
 	// Name can be used to filter the returned characters by their name.
@@ -40,9 +50,7 @@ type CharacterRequest interface {
 
 // NewCharacterRequest returns a new CharacterRequest which can be used to filter characters.
 func NewCharacterRequest() CharacterRequest {
-	c := characterRequest{}
-	c.limit = 10
-	return c
+	return characterRequest{request: newRequest()}
 }
 
 type characterRequest struct {
@@ -57,12 +65,8 @@ type characterRequest struct {
 }
 
 func (request characterRequest) Convert() url.Values {
-	params := url.Values{}
-	params.Add("page", strconv.Itoa(request.limit))
+	params := request.params()
 
-	if request.page != nil {
-		params.Set("pageSize", strconv.Itoa(*request.page))
-	}
 	if request.name != nil {
 		params.Set("name", *request.name)
 	}
@@ -85,11 +89,20 @@ func (request characterRequest) Convert() url.Values {
 	return params
 }
 
-func (request characterRequest) Limit(value int) CharacterRequest {
-	request.limit = value
+func (request characterRequest) Page(value int) CharacterRequest {
+	request.setPage(value)
+	return request
+}
+
+func (request characterRequest) PageSize(value int) CharacterRequest {
+	request.setPageSize(value)
 	return request
 }
 
+func (request characterRequest) Limit(value int) CharacterRequest {
+	return request.PageSize(value)
+}
+
 func (request characterRequest) Name(value string) CharacterRequest {
 	request.name = &value
 	return request
@@ -4,7 +4,10 @@
 
 package goiaf
 
-import "strconv"
+import (
+	"encoding/json"
+	"strconv"
+)
 
 // CharacterResponse contains the data from the performed request.
 //
@@ -13,35 +16,62 @@ import "strconv"
 // the request will return a different result set.
 //
 // Note that, if a result set is not available these methods will return the ErrNoResultSet error.
+//
+// CharacterResponse can be marshaled to and unmarshaled from JSON, the pagination links are kept.
 type CharacterResponse struct {
 	// Data contains the characters from the request.
 	Data []Character
 
-	links map[string]string
+	links Links
+}
+
+type characterResponseJSON struct {
+	Data  []Character `json:"data"`
+	Links Links       `json:"links"`
+}
+
+// MarshalJSON makes the CharacterResponse type implement the json.Marshaler interface.
+func (response CharacterResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(characterResponseJSON{
+		Data:  response.Data,
+		Links: response.links,
+	})
+}
+
+// UnmarshalJSON makes the CharacterResponse type implement the json.Unmarshaler interface.
+func (response *CharacterResponse) UnmarshalJSON(data []byte) error {
+	r := characterResponseJSON{}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	response.Data = r.Data
+	response.links = r.Links
+	return nil
 }
 
 // Next returns a CharacterRequest, which can be used to retrieve
 // the next result set of characters.
 func (response CharacterResponse) Next() (CharacterRequest, error) {
-	return response.getRequestForURL(response.links["next"])
+	return response.getRequestForURL(response.links.URL("next"))
 }
 
 // Prev returns a CharacterRequest, which can be used to retrieve
 // the previous result set of characters.
 func (response CharacterResponse) Prev() (CharacterRequest, error) {
-	return response.getRequestForURL(response.links["prev"])
+	return response.getRequestForURL(response.links.URL("prev"))
 }
 
 // First returns a CharacterRequest, which can be used to retrieve
 // the last result set of characters.
 func (response CharacterResponse) First() (CharacterRequest, error) {
-	return response.getRequestForURL(response.links["first"])
+	return response.getRequestForURL(response.links.URL("first"))
 }
 
 // Last returns a CharacterRequest, which can be used to retrieve
 // the first result set of characters.
 func (response CharacterResponse) Last() (CharacterRequest, error) {
-	return response.getRequestForURL(response.links["last"])
+	return response.getRequestForURL(response.links.URL("last"))
 }
 
 func (response CharacterResponse) getRequestForURL(urlStr string) (CharacterRequest, error) {
@@ -56,8 +86,8 @@ func (response CharacterResponse) getRequestForURL(urlStr string) (CharacterRequ
 	}
 
 	request := characterRequest{}
-	request.limit = page
-	request.page = &pageSize
+	request.setPage(page)
+	request.setPageSize(pageSize)
 
 	if value := query.Get("name"); value != "" {
 		request.name = &value
@@ -4,9 +4,8 @@
 
 package goiaf
 
-// TODO: add functionality for cache headers
-
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -18,10 +17,9 @@ import (
 )
 
 const (
-	baseURL            string = "http://www.anapioficeandfire.com/api"
-	booksEndpoint      string = baseURL + "/books"
-	charactersEndpoint string = baseURL + "/characters"
-	housesEndpoint     string = baseURL + "/houses"
+	defaultBaseURL   string        = "http://www.anapioficeandfire.com/api"
+	defaultTimeout   time.Duration = time.Second * 15
+	defaultUserAgent string        = "goiaf"
 )
 
 // Client interface which reflects the endpoint for the api.
@@ -50,25 +48,98 @@ type Client interface {
 
 	// Return a specific house based on the given id.
 	House(int) (House, error)
+
+	// BooksContext is like Books but uses the given context for the request.
+	// The request is aborted when the context is cancelled or its deadline
+	// is exceeded, in which case the context error is returned.
+	BooksContext(context.Context, BookRequest) (BookResponse, error)
+
+	// BookContext is like Book but uses the given context for the request.
+	BookContext(context.Context, int) (Book, error)
+
+	// CharactersContext is like Characters but uses the given context for the request.
+	CharactersContext(context.Context, CharacterRequest) (CharacterResponse, error)
+
+	// CharacterContext is like Character but uses the given context for the request.
+	CharacterContext(context.Context, int) (Character, error)
+
+	// HousesContext is like Houses but uses the given context for the request.
+	HousesContext(context.Context, HouseRequest) (HouseResponse, error)
+
+	// HouseContext is like House but uses the given context for the request.
+	HouseContext(context.Context, int) (House, error)
 }
 
 type client struct {
-	httpClient http.Client
+	httpClient  *http.Client
+	userAgent   string
+	retryPolicy RetryPolicy
+	rateLimiter *RateLimiter
+	httpCache   *httpCache
+	cassette    *cassetteConfig
+
+	baseURL            string
+	booksEndpoint      string
+	charactersEndpoint string
+	housesEndpoint     string
 }
 
 // NewClient returns a ice and fire client. All endpoints from the api
 // are exposed through this client.
-func NewClient() Client {
-	return &client{
-		httpClient: http.Client{
-			Timeout: time.Second * 15,
+//
+// The client can be configured by passing options, e.g. WithBaseURL to
+// point the client at a mirror of the api or WithHTTPClient to use a custom
+// http.Client. Options are applied in the given order.
+//
+// If the CassetteEnv environment variable is set and no cassette is
+// configured with WithCassette, the traffic of the client is recorded
+// or replayed as described by CassetteEnv and RecordModeEnv.
+func NewClient(opts ...Option) Client {
+	c := &client{
+		httpClient: &http.Client{
+			Timeout: defaultTimeout,
 		},
+		userAgent: defaultUserAgent,
+	}
+	c.setBaseURL(defaultBaseURL)
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	// The cassette wraps the transport only after all options are applied,
+	// so options such as WithTransport do not replace it.
+	if c.cassette == nil {
+		c.cassette = cassetteFromEnv()
+	}
+	if c.cassette != nil {
+		c.httpClient.Transport = c.cassette.transport(c.httpClient.Transport)
+	}
+
+	return c
+}
+
+func (c *client) setBaseURL(baseURL string) {
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	c.baseURL = baseURL
+	c.booksEndpoint = baseURL + "/books"
+	c.charactersEndpoint = baseURL + "/characters"
+	c.housesEndpoint = baseURL + "/houses"
+}
+
+// BaseURL returns the base URL of the api the client sends its requests to.
+func (c *client) BaseURL() string {
+	return c.baseURL
 }
 
 func (c *client) Books(request BookRequest) (BookResponse, error) {
+	return c.BooksContext(context.Background(), request)
+}
+
+func (c *client) BooksContext(ctx context.Context, request BookRequest) (BookResponse, error) {
 	booksResponse := booksResponse{}
-	err := c.get(booksEndpoint, request, &booksResponse)
+	err := c.get(ctx, c.booksEndpoint, request, &booksResponse)
 	if err != nil {
 		return BookResponse{}, err
 	}
@@ -82,10 +153,14 @@ func (c *client) Books(request BookRequest) (BookResponse, error) {
 }
 
 func (c *client) Book(id int) (Book, error) {
-	endpoint := fmt.Sprintf("%s/%d", booksEndpoint, id)
+	return c.BookContext(context.Background(), id)
+}
+
+func (c *client) BookContext(ctx context.Context, id int) (Book, error) {
+	endpoint := fmt.Sprintf("%s/%d", c.booksEndpoint, id)
 
 	book := book{}
-	err := c.get(endpoint, nil, &book)
+	err := c.get(ctx, endpoint, nil, &book)
 	if err != nil {
 		return Book{}, err
 	}
@@ -94,9 +169,13 @@ func (c *client) Book(id int) (Book, error) {
 }
 
 func (c *client) Characters(request CharacterRequest) (CharacterResponse, error) {
+	return c.CharactersContext(context.Background(), request)
+}
+
+func (c *client) CharactersContext(ctx context.Context, request CharacterRequest) (CharacterResponse, error) {
 	charactersResponse := charactersResponse{}
 
-	err := c.get(charactersEndpoint, request, &charactersResponse)
+	err := c.get(ctx, c.charactersEndpoint, request, &charactersResponse)
 	if err != nil {
 		return CharacterResponse{}, err
 	}
@@ -110,10 +189,14 @@ func (c *client) Characters(request CharacterRequest) (CharacterResponse, error)
 }
 
 func (c *client) Character(id int) (Character, error) {
-	endpoint := fmt.Sprintf("%s/%d", charactersEndpoint, id)
+	return c.CharacterContext(context.Background(), id)
+}
+
+func (c *client) CharacterContext(ctx context.Context, id int) (Character, error) {
+	endpoint := fmt.Sprintf("%s/%d", c.charactersEndpoint, id)
 
 	character := character{}
-	err := c.get(endpoint, nil, &character)
+	err := c.get(ctx, endpoint, nil, &character)
 	if err != nil {
 		return Character{}, err
 	}
@@ -122,9 +205,13 @@ func (c *client) Character(id int) (Character, error) {
 }
 
 func (c *client) Houses(request HouseRequest) (HouseResponse, error) {
+	return c.HousesContext(context.Background(), request)
+}
+
+func (c *client) HousesContext(ctx context.Context, request HouseRequest) (HouseResponse, error) {
 	housesResponse := housesResponse{}
 
-	err := c.get(housesEndpoint, request, &housesResponse)
+	err := c.get(ctx, c.housesEndpoint, request, &housesResponse)
 	if err != nil {
 		return HouseResponse{}, err
 	}
@@ -138,10 +225,14 @@ func (c *client) Houses(request HouseRequest) (HouseResponse, error) {
 }
 
 func (c *client) House(id int) (House, error) {
-	endpoint := fmt.Sprintf("%s/%d", housesEndpoint, id)
+	return c.HouseContext(context.Background(), id)
+}
+
+func (c *client) HouseContext(ctx context.Context, id int) (House, error) {
+	endpoint := fmt.Sprintf("%s/%d", c.housesEndpoint, id)
 
 	house := house{}
-	err := c.get(endpoint, nil, &house)
+	err := c.get(ctx, endpoint, nil, &house)
 	if err != nil {
 		return House{}, err
 	}
@@ -149,60 +240,143 @@ func (c *client) House(id int) (House, error) {
 	return house.Convert(), nil
 }
 
-func (c *client) get(endpoint string, converter ParamConverter, data interface{}) error {
+func (c *client) get(ctx context.Context, endpoint string, converter ParamConverter, data interface{}) error {
+	if v, ok := converter.(validator); ok {
+		if err := v.validate(); err != nil {
+			return err
+		}
+	}
 	if converter != nil {
 		endpoint = fmt.Sprintf("%s?%s", endpoint, converter.Convert().Encode())
 	}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+	resp, err := c.fetch(ctx, endpoint)
 	if err != nil {
 		return err
 	}
-	req.Close = true
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
+	if t, ok := data.(linker); ok {
+		base, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		links, err := ParseLinks(resp.header.Get("Link"), base)
+		if err != nil {
+			return err
+		}
+		t.Link(links)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return ErrResourceNotFound
+	return json.Unmarshal(resp.body, data)
+}
+
+// response is a successful response from the api with its body already read.
+type response struct {
+	header http.Header
+	body   []byte
+
+	// notModified is set if the api responded with 304 Not Modified
+	// to a conditional request, in which case body is empty.
+	notModified bool
+}
+
+// fetch performs a GET request against the endpoint. If the HTTP cache is
+// enabled, a fresh cached response is returned without a request and a stale
+// one is revalidated.
+func (c *client) fetch(ctx context.Context, endpoint string) (*response, error) {
+	if c.httpCache == nil {
+		return c.fetchWithRetries(ctx, endpoint, nil)
 	}
 
-	if t, ok := data.(linker); ok {
-		t.Link(c.getLinks(resp.Header.Get("link")))
+	entry := c.httpCache.get(endpoint)
+	if entry != nil && entry.fresh(time.Now()) {
+		return entry.response(), nil
 	}
 
-	b, err := ioutil.ReadAll(resp.Body)
+	resp, err := c.fetchWithRetries(ctx, endpoint, entry)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return json.Unmarshal(b, data)
+	if resp.notModified {
+		c.httpCache.revalidated(endpoint, entry, resp, time.Now())
+		return entry.response(), nil
+	}
+
+	c.httpCache.store(endpoint, resp, time.Now())
+
+	return resp, nil
 }
 
-func (c *client) getLinks(linkHeader string) map[string]string {
-	result := map[string]string{}
-	if linkHeader == "" {
-		return result
+// fetchWithRetries performs a GET request against the endpoint, retrying it
+// according to the retry policy of the client. Every attempt waits for the
+// rate limiter of the client, if any. If entry is not nil the request is
+// made conditional on its validators.
+func (c *client) fetchWithRetries(ctx context.Context, endpoint string, entry *cacheEntry) (*response, error) {
+	for attempt := 1; ; attempt++ {
+		if c.rateLimiter != nil {
+			if err := c.rateLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.do(ctx, endpoint, entry)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= c.retryPolicy.MaxAttempts || !c.retryPolicy.retryable(err) {
+			return nil, err
+		}
+
+		wait, ok := c.retryPolicy.backoff(attempt, err)
+		if !ok {
+			return nil, err
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
 	}
+}
 
-	links := strings.Split(linkHeader, ",")
-	for _, link := range links {
-		link = strings.TrimSpace(link)
-		linkPair := strings.Split(link, ";")
+// do performs a single GET request against the endpoint.
+func (c *client) do(ctx context.Context, endpoint string, entry *cacheEntry) (*response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Close = true
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if entry != nil {
+		entry.setRequestHeaders(req)
+	}
 
-		urlStr := strings.TrimSpace(linkPair[0])
-		rel := strings.Replace(strings.TrimSpace(linkPair[1]), "rel=", "", 1)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-		urlStr = urlStr[1 : len(urlStr)-1]
-		rel = rel[1 : len(rel)-1]
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		return &response{header: resp.Header, notModified: true}, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newAPIError(resp)
+	}
 
-		result[rel] = urlStr
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
 	}
 
-	return result
+	return &response{header: resp.Header, body: b}, nil
 }
 
 func getQueryFromURL(urlStr string) (url.Values, error) {
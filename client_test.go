@@ -0,0 +1,152 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/goiaftest"
+)
+
+// fastRetries retries quickly, so tests do not wait.
+var fastRetries = goiaf.RetryPolicy{
+	MaxAttempts:        3,
+	BaseBackoff:        time.Millisecond,
+	MaxBackoff:         10 * time.Millisecond,
+	RetryableStatuses:  goiaf.DefaultRetryPolicy.RetryableStatuses,
+	RetryNetworkErrors: true,
+}
+
+func TestClientRetriesRetryableStatuses(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		server := newTestServer(t)
+		server.AddFault(goiaftest.Fault{StatusCode: status, Times: 2})
+
+		character, err := server.Client(goiaf.WithRetryPolicy(fastRetries)).Character(1)
+		if err != nil {
+			t.Errorf("status %d: Character(1) returned %v, want success after retries", status, err)
+			continue
+		}
+		if character.Name != "Character 1" {
+			t.Errorf("status %d: Character(1).Name = %q, want Character 1", status, character.Name)
+		}
+		if server.Requests() != 3 {
+			t.Errorf("status %d: server received %d requests, want 3", status, server.Requests())
+		}
+	}
+}
+
+func TestClientGivesUpAfterMaxAttempts(t *testing.T) {
+	server := newTestServer(t)
+	server.AddFault(goiaftest.Fault{StatusCode: http.StatusInternalServerError})
+
+	_, err := server.Client(goiaf.WithRetryPolicy(fastRetries)).Book(1)
+	if !errors.Is(err, goiaf.ErrServerError) {
+		t.Errorf("Book(1) returned %v, want ErrServerError", err)
+	}
+	if server.Requests() != fastRetries.MaxAttempts {
+		t.Errorf("server received %d requests, want %d", server.Requests(), fastRetries.MaxAttempts)
+	}
+}
+
+func TestClientDoesNotRetryNotFound(t *testing.T) {
+	server := newTestServer(t)
+
+	_, err := server.Client(goiaf.WithRetryPolicy(fastRetries)).Character(999)
+	if !errors.Is(err, goiaf.ErrResourceNotFound) {
+		t.Errorf("Character(999) returned %v, want ErrResourceNotFound", err)
+	}
+	if server.Requests() != 1 {
+		t.Errorf("server received %d requests, want 1", server.Requests())
+	}
+}
+
+func TestClientFailsOnLongRetryAfter(t *testing.T) {
+	server := newTestServer(t)
+	server.AddFault(goiaftest.Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: 24 * time.Hour})
+
+	start := time.Now()
+	_, err := server.Client(goiaf.WithRetryPolicy(fastRetries)).House(1)
+
+	var apiErr *goiaf.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("House(1) returned %v, want the APIError of the 429 response", err)
+	}
+	if !errors.Is(err, goiaf.ErrRateLimited) {
+		t.Errorf("House(1) returned %v, want ErrRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("House(1) took %v, want it to fail without waiting", elapsed)
+	}
+	if server.Requests() != 1 {
+		t.Errorf("server received %d requests, want 1", server.Requests())
+	}
+}
+
+func TestClientCancelledDuringBackoff(t *testing.T) {
+	server := newTestServer(t)
+	server.AddFault(goiaftest.Fault{StatusCode: http.StatusServiceUnavailable})
+
+	policy := fastRetries
+	policy.BaseBackoff, policy.MaxBackoff = time.Minute, time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := server.Client(goiaf.WithRetryPolicy(policy)).CharacterContext(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CharacterContext returned %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CharacterContext took %v, want it to stop when the context is done", elapsed)
+	}
+	if server.Requests() != 1 {
+		t.Errorf("server received %d requests, want 1", server.Requests())
+	}
+}
+
+func TestClientReturnsAPIError(t *testing.T) {
+	server := newTestServer(t)
+
+	_, err := server.Client().Character(1000)
+	if !errors.Is(err, goiaf.ErrResourceNotFound) {
+		t.Errorf("Character(1000) returned %v, want an error matching ErrResourceNotFound", err)
+	}
+	if err == goiaf.ErrResourceNotFound {
+		t.Error("Character(1000) returned the sentinel error, want an APIError")
+	}
+
+	var apiErr *goiaf.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Character(1000) returned %T, want *goiaf.APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Method != http.MethodGet || apiErr.URL != server.BaseURL()+"/characters/1000" {
+		t.Errorf("APIError = %+v, want a GET of /characters/1000 with status 404", apiErr)
+	}
+}
+
+func TestOfflineClientReturnsAPIError(t *testing.T) {
+	fixtures := testFixtures()
+	client := goiaf.NewOfflineClient(fixtures.Books, fixtures.Characters, fixtures.Houses)
+
+	_, err := client.House(1000)
+	if !errors.Is(err, goiaf.ErrResourceNotFound) {
+		t.Errorf("House(1000) returned %v, want an error matching ErrResourceNotFound", err)
+	}
+
+	var apiErr *goiaf.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("House(1000) returned %T, want *goiaf.APIError like the client", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Method != http.MethodGet || apiErr.URL != fixtureBaseURL+"/houses/1000" {
+		t.Errorf("APIError = %+v, want a GET of /houses/1000 with status 404", apiErr)
+	}
+}
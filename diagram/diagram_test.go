@@ -0,0 +1,194 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagram
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/genealogy"
+	"github.com/mattiaspernhult/goiaf/internal/testfixture"
+)
+
+// family returns Eddard, Catelyn and their son known only by an alias.
+func family() *genealogy.FamilyGraph {
+	return genealogy.NewFamilyGraph([]goiaf.Character{
+		{URL: testfixture.CharacterURL(1), Name: `Eddard "Ned" Stark`, SpouseID: testfixture.CharacterRef(2), AllegianceIds: []goiaf.HouseID{362}},
+		{URL: testfixture.CharacterURL(2), Name: "Catelyn", Died: "299 AC"},
+		{URL: testfixture.CharacterURL(3), Aliases: []string{"", "The Young Wolf"}, FatherID: testfixture.CharacterRef(1), MotherID: testfixture.CharacterRef(2)},
+		{URL: testfixture.CharacterURL(4), Name: "Unknown father", FatherID: testfixture.CharacterRef(100)},
+	})
+}
+
+func familyGraph() *Graph {
+	houses := []goiaf.House{{URL: testfixture.HouseURL(362), Name: "House Stark of Winterfell"}}
+	return Family(family(), WithCharacterStyle(MarkDead), WithAllegianceClusters(houses))
+}
+
+func TestFamilyDOT(t *testing.T) {
+	want := `digraph "family" {
+	subgraph "cluster_h362" {
+		label="House Stark of Winterfell";
+		"c1" [label="Eddard \"Ned\" Stark"];
+	}
+	"c2" [label="Catelyn", color="#808080", fontcolor="#808080", style="dashed"];
+	"c3" [label="The Young Wolf"];
+	"c4" [label="Unknown father"];
+	"c1" -> "c2" [label="spouse", dir=none, style="dashed"];
+	"c1" -> "c3";
+	"c2" -> "c3";
+}
+`
+	if got := familyGraph().DOT(); got != want {
+		t.Errorf("DOT() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFamilyMermaid(t *testing.T) {
+	want := `flowchart TB
+	subgraph cluster_h362["House Stark of Winterfell"]
+		c1("Eddard #quot;Ned#quot; Stark")
+	end
+	c2("Catelyn")
+	c3("The Young Wolf")
+	c4("Unknown father")
+	c1 -.-|"spouse"| c2
+	c1 --> c3
+	c2 --> c3
+	style c2 stroke:#808080,color:#808080,stroke-dasharray:5 5
+`
+	if got := familyGraph().Mermaid(); got != want {
+		t.Errorf("Mermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteDOTMatchesDOT(t *testing.T) {
+	g := familyGraph()
+
+	var b bytes.Buffer
+	if err := g.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != g.DOT() {
+		t.Error("WriteDOT and DOT differ")
+	}
+}
+
+func TestFealty(t *testing.T) {
+	tree := genealogy.NewHouseTree([]goiaf.House{
+		{URL: testfixture.HouseURL(1), Name: "Iron Throne"},
+		{URL: testfixture.HouseURL(2), Name: "Stark", Region: "The North", OverlordID: testfixture.HouseRef(1), CadetBranchesIds: []goiaf.HouseID{3}},
+		{URL: testfixture.HouseURL(3), Name: "Karstark", Region: "The North", OverlordID: testfixture.HouseRef(2)},
+	})
+	g := Fealty(tree, WithHouseStyle(ColorByRegion))
+
+	if len(g.Nodes) != 3 || g.Nodes[0].Style.Shape != "box" {
+		t.Fatalf("Nodes = %+v, want three boxes", g.Nodes)
+	}
+	if g.Nodes[0].Style.FillColor != "" || g.Nodes[1].Style.FillColor == "" || g.Nodes[1].Style.FillColor != g.Nodes[2].Style.FillColor {
+		t.Errorf("Nodes = %+v, want the houses of the North filled with the same color", g.Nodes)
+	}
+
+	mermaid := g.Mermaid()
+	for _, line := range []string{
+		"\th1[\"Iron Throne\"]\n",
+		"\th1 --> h2\n",
+		"\th2 --> h3\n",
+		"\th2 -.->|\"cadet branch\"| h3\n",
+	} {
+		if !strings.Contains(mermaid, line) {
+			t.Errorf("Mermaid() does not contain %q:\n%s", line, mermaid)
+		}
+	}
+}
+
+func TestAppearances(t *testing.T) {
+	books := []goiaf.Book{
+		{URL: testfixture.BookURL(2), Name: "A Clash of Kings", CharacterIds: []goiaf.CharacterID{1, 2}, PovCharacterIds: []goiaf.CharacterID{2}},
+		{URL: testfixture.BookURL(1), Name: "A Game of Thrones", CharacterIds: []goiaf.CharacterID{1, 99}},
+	}
+	characters := []goiaf.Character{
+		{URL: testfixture.CharacterURL(1), Name: "Eddard"},
+		{URL: testfixture.CharacterURL(2), Name: "Catelyn"},
+	}
+
+	want := `digraph "appearances" {
+	"b1" [label="A Game of Thrones", shape=box];
+	"b2" [label="A Clash of Kings", shape=box];
+	"c1" [label="Eddard"];
+	"c2" [label="Catelyn"];
+	"b1" -> "c1";
+	"b2" -> "c2" [label="POV", style="bold"];
+	"b2" -> "c1";
+}
+`
+	if got := Appearances(books, characters).DOT(); got != want {
+		t.Errorf("DOT() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Appearances(books, characters).Mermaid(); !strings.Contains(got, "\tb2 ==>|\"POV\"| c2\n") {
+		t.Errorf("Mermaid() does not draw the POV edge bold:\n%s", got)
+	}
+}
+
+func TestDOTQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Jon Snow", `"Jon Snow"`},
+		{`Eddard "Ned" Stark`, `"Eddard \"Ned\" Stark"`},
+		{`C:\Winterfell`, `"C:\\Winterfell"`},
+		{`\"`, `"\\\""`},
+		{"The Young Wolf\nKing in the North", `"The Young Wolf\nKing in the North"`},
+		{"", `""`},
+	}
+
+	for _, test := range tests {
+		if got := dotQuote(test.in); got != test.want {
+			t.Errorf("dotQuote(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+}
+
+func TestMermaidQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Jon Snow", `"Jon Snow"`},
+		{`Eddard "Ned" Stark`, `"Eddard #quot;Ned#quot; Stark"`},
+		{`C:\Winterfell`, `"C:\Winterfell"`},
+		{"The Young Wolf\nKing in the North", `"The Young Wolf<br>King in the North"`},
+		{"#quot;", `"#35;quot;"`},
+		{"<b>Bold</b>", `"#lt;b#gt;Bold#lt;/b#gt;"`},
+	}
+
+	for _, test := range tests {
+		if got := mermaidQuote(test.in); got != test.want {
+			t.Errorf("mermaidQuote(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+}
+
+func TestMermaidEdge(t *testing.T) {
+	tests := []struct {
+		edge Edge
+		want string
+	}{
+		{Edge{From: "a", To: "b"}, "a --> b"},
+		{Edge{From: "a", To: "b", Undirected: true}, "a --- b"},
+		{Edge{From: "a", To: "b", Style: Style{Dashed: true}}, "a -.-> b"},
+		{Edge{From: "a", To: "b", Style: Style{Dashed: true}, Undirected: true}, "a -.- b"},
+		{Edge{From: "a", To: "b", Style: Style{Bold: true}}, "a ==> b"},
+		{Edge{From: "a", To: "b", Style: Style{Bold: true}, Undirected: true}, "a === b"},
+		{Edge{From: "a", To: "b", Label: `say "hi"`}, `a -->|"say #quot;hi#quot;"| b`},
+	}
+
+	for _, test := range tests {
+		if got := mermaidEdge(test.edge); got != test.want {
+			t.Errorf("mermaidEdge(%+v) = %q, want %q", test.edge, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagram
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the graph as a Graphviz digraph to w.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(g.Name))
+	for _, cluster := range g.Clusters {
+		fmt.Fprintf(bw, "\tsubgraph %s {\n", dotQuote(cluster.ID))
+		fmt.Fprintf(bw, "\t\tlabel=%s;\n", dotQuote(cluster.Label))
+		for _, node := range g.Nodes {
+			if node.Cluster == cluster.ID {
+				fmt.Fprintf(bw, "\t\t%s;\n", dotNode(node))
+			}
+		}
+		fmt.Fprintf(bw, "\t}\n")
+	}
+	for _, node := range g.Nodes {
+		if node.Cluster == "" {
+			fmt.Fprintf(bw, "\t%s;\n", dotNode(node))
+		}
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(bw, "\t%s;\n", dotEdge(edge))
+	}
+	fmt.Fprintf(bw, "}\n")
+
+	return bw.Flush()
+}
+
+// DOT returns the graph as a Graphviz digraph.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	g.WriteDOT(&b)
+	return b.String()
+}
+
+func dotNode(node Node) string {
+	attrs := []string{"label=" + dotQuote(node.Label)}
+	if node.Style.Shape != "" {
+		attrs = append(attrs, "shape="+node.Style.Shape)
+	}
+	attrs = append(attrs, dotStyle(node.Style)...)
+
+	return dotQuote(node.ID) + " [" + strings.Join(attrs, ", ") + "]"
+}
+
+func dotEdge(edge Edge) string {
+	attrs := []string{}
+	if edge.Label != "" {
+		attrs = append(attrs, "label="+dotQuote(edge.Label))
+	}
+	if edge.Undirected {
+		attrs = append(attrs, "dir=none")
+	}
+	attrs = append(attrs, dotStyle(edge.Style)...)
+
+	line := dotQuote(edge.From) + " -> " + dotQuote(edge.To)
+	if len(attrs) > 0 {
+		line += " [" + strings.Join(attrs, ", ") + "]"
+	}
+
+	return line
+}
+
+func dotStyle(style Style) []string {
+	attrs := []string{}
+	if style.Color != "" {
+		attrs = append(attrs, "color="+dotQuote(style.Color))
+	}
+	if style.FillColor != "" {
+		attrs = append(attrs, "fillcolor="+dotQuote(style.FillColor))
+	}
+	if style.FontColor != "" {
+		attrs = append(attrs, "fontcolor="+dotQuote(style.FontColor))
+	}
+
+	styles := []string{}
+	if style.FillColor != "" {
+		styles = append(styles, "filled")
+	}
+	if style.Dashed {
+		styles = append(styles, "dashed")
+	}
+	if style.Bold {
+		styles = append(styles, "bold")
+	}
+	if len(styles) > 0 {
+		attrs = append(attrs, "style="+dotQuote(strings.Join(styles, ",")))
+	}
+
+	return attrs
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return `"` + s + `"`
+}
@@ -0,0 +1,332 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package diagram renders family trees, feudal hierarchies and book
+appearances as Graphviz DOT or Mermaid flowchart text.
+
+A diagram is first built as a Graph, which can then be written in either
+format:
+
+	family, err := genealogy.FetchFamilyGraph(ctx, client)
+	checkErr(err)
+
+	graph := diagram.Family(family, diagram.WithCharacterStyle(diagram.MarkDead))
+	err = graph.WriteDOT(os.Stdout)
+	checkErr(err)
+*/
+package diagram
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/genealogy"
+)
+
+// Node is a node of a Graph.
+type Node struct {
+	ID    string
+	Label string
+	Style Style
+
+	// The id of the cluster the node is drawn in, or empty.
+	Cluster string
+}
+
+// Edge is an edge of a Graph.
+type Edge struct {
+	From  string
+	To    string
+	Label string
+	Style Style
+
+	// Whether the edge is drawn without arrow head.
+	Undirected bool
+}
+
+// Cluster is a group of nodes drawn together, such as the members of a house.
+type Cluster struct {
+	ID    string
+	Label string
+}
+
+// Graph is a diagram which can be written as DOT or Mermaid.
+type Graph struct {
+	Name     string
+	Nodes    []Node
+	Edges    []Edge
+	Clusters []Cluster
+}
+
+// Option is used to configure how a Graph is built.
+type Option func(*options)
+
+type options struct {
+	characterStyles []func(goiaf.Character) Style
+	houseStyles     []func(goiaf.House) Style
+
+	clusters bool
+	houses   map[goiaf.HouseID]goiaf.House
+}
+
+// WithCharacterStyle adds a hook which styles the node of a character.
+// Hooks are applied in order, fields set by later hooks take precedence.
+func WithCharacterStyle(fn func(goiaf.Character) Style) Option {
+	return func(o *options) {
+		o.characterStyles = append(o.characterStyles, fn)
+	}
+}
+
+// WithHouseStyle adds a hook which styles the node of a house. Hooks are
+// applied in order, fields set by later hooks take precedence.
+func WithHouseStyle(fn func(goiaf.House) Style) Option {
+	return func(o *options) {
+		o.houseStyles = append(o.houseStyles, fn)
+	}
+}
+
+// WithAllegianceClusters draws characters grouped by the first house in their
+// AllegianceIds. The houses are used to label the clusters.
+func WithAllegianceClusters(houses []goiaf.House) Option {
+	return func(o *options) {
+		o.clusters = true
+		for _, house := range houses {
+			if id, ok := house.ID(); ok {
+				o.houses[id] = house
+			}
+		}
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{houses: map[goiaf.HouseID]goiaf.House{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// Family builds a diagram of the characters of the family graph, with edges
+// from parents to children and between spouses. Links to characters which
+// are not part of the family graph are left out.
+func Family(g *genealogy.FamilyGraph, opts ...Option) *Graph {
+	o := newOptions(opts)
+	b := newBuilder("family", o)
+
+	for _, id := range g.Characters() {
+		character, _ := g.Character(id)
+		b.character(id, character)
+	}
+
+	for _, id := range g.Characters() {
+		for _, parent := range g.Parents(id) {
+			if _, ok := g.Character(parent); ok {
+				b.edge(Edge{From: characterNode(parent), To: characterNode(id)})
+			}
+		}
+		for _, spouse := range g.Spouses(id) {
+			if _, ok := g.Character(spouse); ok && id < spouse {
+				b.edge(Edge{
+					From:       characterNode(id),
+					To:         characterNode(spouse),
+					Label:      "spouse",
+					Style:      Style{Dashed: true},
+					Undirected: true,
+				})
+			}
+		}
+	}
+
+	return b.graph
+}
+
+// Fealty builds a diagram of the houses of the tree, with edges from
+// overlords to their vassals and dashed edges from houses to their cadet
+// branches.
+func Fealty(t *genealogy.HouseTree, opts ...Option) *Graph {
+	o := newOptions(opts)
+	b := newBuilder("fealty", o)
+
+	for _, id := range t.Houses() {
+		house, _ := t.House(id)
+		b.house(id, house)
+	}
+
+	for _, id := range t.Houses() {
+		for _, vassal := range t.DirectVassals(id) {
+			if _, ok := t.House(vassal); ok {
+				b.edge(Edge{From: houseNode(id), To: houseNode(vassal)})
+			}
+		}
+		for _, cadet := range t.DirectCadetBranches(id) {
+			if _, ok := t.House(cadet); ok {
+				b.edge(Edge{
+					From:  houseNode(id),
+					To:    houseNode(cadet),
+					Label: "cadet branch",
+					Style: Style{Dashed: true},
+				})
+			}
+		}
+	}
+
+	return b.graph
+}
+
+// Appearances builds a diagram of the books and the characters appearing in
+// them, with bold edges for POV characters. Only the given characters are
+// drawn, so passing a subset of the characters limits the diagram to them.
+// Books and characters without a valid id are left out.
+func Appearances(books []goiaf.Book, characters []goiaf.Character, opts ...Option) *Graph {
+	o := newOptions(opts)
+	b := newBuilder("appearances", o)
+
+	known := map[goiaf.CharacterID]goiaf.Character{}
+	for _, character := range characters {
+		if id, ok := character.ID(); ok {
+			known[id] = character
+		}
+	}
+
+	booksByID := map[goiaf.BookID]goiaf.Book{}
+	bookIDs := []goiaf.BookID{}
+	for _, book := range books {
+		if id, ok := book.ID(); ok {
+			booksByID[id] = book
+			bookIDs = append(bookIDs, id)
+		}
+	}
+	sort.SliceStable(bookIDs, func(i, j int) bool { return bookIDs[i] < bookIDs[j] })
+
+	drawn := map[goiaf.CharacterID]bool{}
+	edges := []Edge{}
+	for _, bookID := range bookIDs {
+		book := booksByID[bookID]
+		b.node(Node{ID: bookNode(bookID), Label: label(book.Name, nil, "Book", int(bookID)), Style: Style{Shape: "box"}})
+
+		pov := map[goiaf.CharacterID]bool{}
+		for _, id := range book.PovCharacterIds {
+			pov[id] = true
+		}
+
+		ids := append(append([]goiaf.CharacterID{}, book.PovCharacterIds...), book.CharacterIds...)
+		seen := map[goiaf.CharacterID]bool{}
+		for _, id := range ids {
+			if _, ok := known[id]; !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			drawn[id] = true
+
+			edge := Edge{From: bookNode(bookID), To: characterNode(id)}
+			if pov[id] {
+				edge.Label = "POV"
+				edge.Style = Style{Bold: true}
+			}
+			edges = append(edges, edge)
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(drawn)) {
+		b.character(id, known[id])
+	}
+	for _, edge := range edges {
+		b.edge(edge)
+	}
+
+	return b.graph
+}
+
+type builder struct {
+	graph    *Graph
+	options  *options
+	clusters map[string]bool
+}
+
+func newBuilder(name string, o *options) *builder {
+	return &builder{
+		graph:    &Graph{Name: name},
+		options:  o,
+		clusters: map[string]bool{},
+	}
+}
+
+func (b *builder) node(node Node) {
+	b.graph.Nodes = append(b.graph.Nodes, node)
+}
+
+func (b *builder) edge(edge Edge) {
+	b.graph.Edges = append(b.graph.Edges, edge)
+}
+
+func (b *builder) character(id goiaf.CharacterID, character goiaf.Character) {
+	node := Node{
+		ID:    characterNode(id),
+		Label: label(character.Name, character.Aliases, "Character", int(id)),
+	}
+	for _, fn := range b.options.characterStyles {
+		node.Style = node.Style.merge(fn(character))
+	}
+
+	if b.options.clusters && len(character.AllegianceIds) > 0 {
+		allegiance := character.AllegianceIds[0]
+		node.Cluster = "cluster_" + houseNode(allegiance)
+		if !b.clusters[node.Cluster] {
+			b.clusters[node.Cluster] = true
+
+			house := b.options.houses[allegiance]
+			b.graph.Clusters = append(b.graph.Clusters, Cluster{
+				ID:    node.Cluster,
+				Label: label(house.Name, nil, "House", int(allegiance)),
+			})
+		}
+	}
+
+	b.node(node)
+}
+
+func (b *builder) house(id goiaf.HouseID, house goiaf.House) {
+	node := Node{
+		ID:    houseNode(id),
+		Label: label(house.Name, nil, "House", int(id)),
+		Style: Style{Shape: "box"},
+	}
+	for _, fn := range b.options.houseStyles {
+		node.Style = node.Style.merge(fn(house))
+	}
+
+	b.node(node)
+}
+
+// label returns the name, or the first alias if the name is unknown, or a
+// label made from the kind and id of the resource.
+func label(name string, aliases []string, kind string, id int) string {
+	if name != "" {
+		return name
+	}
+	for _, alias := range aliases {
+		if alias != "" {
+			return alias
+		}
+	}
+
+	return fmt.Sprintf("%s %d", kind, id)
+}
+
+func characterNode(id goiaf.CharacterID) string {
+	return "c" + id.String()
+}
+
+func houseNode(id goiaf.HouseID) string {
+	return "h" + id.String()
+}
+
+func bookNode(id goiaf.BookID) string {
+	return "b" + id.String()
+}
@@ -0,0 +1,129 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagram
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMermaid writes the graph as a Mermaid flowchart to w.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "flowchart TB\n")
+	for _, cluster := range g.Clusters {
+		fmt.Fprintf(bw, "\tsubgraph %s[%s]\n", cluster.ID, mermaidQuote(cluster.Label))
+		for _, node := range g.Nodes {
+			if node.Cluster == cluster.ID {
+				fmt.Fprintf(bw, "\t\t%s\n", mermaidNode(node))
+			}
+		}
+		fmt.Fprintf(bw, "\tend\n")
+	}
+	for _, node := range g.Nodes {
+		if node.Cluster == "" {
+			fmt.Fprintf(bw, "\t%s\n", mermaidNode(node))
+		}
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(bw, "\t%s\n", mermaidEdge(edge))
+	}
+
+	for _, node := range g.Nodes {
+		if style := mermaidStyle(node.Style); style != "" {
+			fmt.Fprintf(bw, "\tstyle %s %s\n", node.ID, style)
+		}
+	}
+	for i, edge := range g.Edges {
+		// Dashed and bold edges are expressed by their arrows, and edges
+		// have nothing to fill.
+		edge.Style.Dashed, edge.Style.Bold, edge.Style.FillColor = false, false, ""
+		if style := mermaidStyle(edge.Style); style != "" {
+			fmt.Fprintf(bw, "\tlinkStyle %d %s\n", i, style)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Mermaid returns the graph as a Mermaid flowchart.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	g.WriteMermaid(&b)
+	return b.String()
+}
+
+func mermaidNode(node Node) string {
+	label := mermaidQuote(node.Label)
+
+	switch node.Style.Shape {
+	case "box":
+		return node.ID + "[" + label + "]"
+	case "circle":
+		return node.ID + "((" + label + "))"
+	}
+
+	return node.ID + "(" + label + ")"
+}
+
+func mermaidEdge(edge Edge) string {
+	var arrow string
+	switch {
+	case edge.Style.Dashed && edge.Undirected:
+		arrow = "-.-"
+	case edge.Style.Dashed:
+		arrow = "-.->"
+	case edge.Style.Bold && edge.Undirected:
+		arrow = "==="
+	case edge.Style.Bold:
+		arrow = "==>"
+	case edge.Undirected:
+		arrow = "---"
+	default:
+		arrow = "-->"
+	}
+	if edge.Label != "" {
+		arrow += "|" + mermaidQuote(edge.Label) + "|"
+	}
+
+	return edge.From + " " + arrow + " " + edge.To
+}
+
+// mermaidStyle returns the style as Mermaid style properties.
+func mermaidStyle(style Style) string {
+	props := []string{}
+	if style.FillColor != "" {
+		props = append(props, "fill:"+style.FillColor)
+	}
+	if style.Color != "" {
+		props = append(props, "stroke:"+style.Color)
+	}
+	if style.FontColor != "" {
+		props = append(props, "color:"+style.FontColor)
+	}
+	if style.Dashed {
+		props = append(props, "stroke-dasharray:5 5")
+	}
+	if style.Bold {
+		props = append(props, "stroke-width:3px")
+	}
+
+	return strings.Join(props, ",")
+}
+
+// mermaidQuote quotes the label, writing characters Mermaid would interpret
+// as entity codes. The # is replaced first, as the codes start with it.
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, "#", "#35;")
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "<", "#lt;")
+	s = strings.ReplaceAll(s, ">", "#gt;")
+	s = strings.ReplaceAll(s, "\n", "<br>")
+
+	return `"` + s + `"`
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagram
+
+import (
+	"hash/fnv"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+// Style describes how a node or an edge is drawn. Empty fields keep the
+// default of the output format. Colors are given as "#rrggbb".
+type Style struct {
+	Color     string
+	FillColor string
+	FontColor string
+
+	// The shape of a node, using the Graphviz names "box", "ellipse"
+	// and "circle".
+	Shape string
+
+	Dashed bool
+	Bold   bool
+}
+
+// merge returns the style with the fields set in other replacing its own.
+func (s Style) merge(other Style) Style {
+	if other.Color != "" {
+		s.Color = other.Color
+	}
+	if other.FillColor != "" {
+		s.FillColor = other.FillColor
+	}
+	if other.FontColor != "" {
+		s.FontColor = other.FontColor
+	}
+	if other.Shape != "" {
+		s.Shape = other.Shape
+	}
+	s.Dashed = s.Dashed || other.Dashed
+	s.Bold = s.Bold || other.Bold
+
+	return s
+}
+
+// regionColors are the fill colors used by ColorByRegion.
+var regionColors = []string{
+	"#8dd3c7", "#ffffb3", "#bebada", "#fb8072", "#80b1d3", "#fdb462",
+	"#b3de69", "#fccde5", "#d9d9d9", "#bc80bd", "#ccebc5", "#ffed6f",
+}
+
+// MarkDead draws characters with a known death grey and dashed. It can be
+// passed to WithCharacterStyle.
+func MarkDead(character goiaf.Character) Style {
+	if character.Died == "" {
+		return Style{}
+	}
+
+	return Style{Color: "#808080", FontColor: "#808080", Dashed: true}
+}
+
+// ColorByRegion fills houses with a color picked from their region, so
+// houses of the same region share a color. It can be passed to
+// WithHouseStyle.
+func ColorByRegion(house goiaf.House) Style {
+	if house.Region == "" {
+		return Style{}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(house.Region))
+
+	return Style{FillColor: regionColors[h.Sum32()%uint32(len(regionColors))]}
+}
@@ -50,5 +50,41 @@ This API includes all the data from the universe of Ice And Fire you've ever wan
 	house, err := client.Houses(378)
 	checkErr(err)
 	fmt.Printf("%+v\n", house)
+
+Every method on the client also has a variant that takes a context.Context as
+its first argument. The request is aborted as soon as the context is cancelled
+or its deadline is exceeded, and the context error is returned.
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	character, err = client.CharacterContext(ctx, 583)
+	checkErr(err)
+
+When the api responds with an error status, the client returns an *APIError
+holding the status code, the headers and the beginning of the body. Earlier
+versions returned ErrResourceNotFound itself, so a comparison with == no longer
+matches and errors.Is must be used instead. The offline client returns an
+*APIError with status 404 for a resource which does not exist, like the api.
+
+	_, err = client.Character(1000000)
+	if errors.Is(err, goiaf.ErrResourceNotFound) {
+		fmt.Println("no such character")
+	}
+
+	var apiErr *goiaf.APIError
+	if errors.As(err, &apiErr) {
+		fmt.Println(apiErr.StatusCode, apiErr.Header.Get("Retry-After"))
+	}
+
+Iterating over every page of a result set does not require calling Next() by
+hand. AllBooks, AllCharacters and AllHouses follow the pagination of the api
+and can be used in a range loop, NewBookIterator, NewCharacterIterator and
+NewHouseIterator return iterators with Next, Value and Err methods.
+
+	for character, err := range goiaf.AllCharacters(ctx, client, goiaf.NewCharacterRequest().IsAlive(true)) {
+		checkErr(err)
+		fmt.Printf("%+v\n", character)
+	}
 */
 package goiaf
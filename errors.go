@@ -4,17 +4,104 @@
 
 package goiaf
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// maxErrorBodySize is the maximum number of bytes of the response body
+	// which is kept in an APIError.
+	maxErrorBodySize = 1024
+)
 
 var (
 	// ErrResourceNotFound will be used if the response from the api has 404 as HTTP status.
+	// The client returns an APIError in that case, which can be checked against this
+	// error with errors.Is. Comparing the returned error with == does not match, unlike
+	// in earlier versions of the client. The offline client returns an APIError as well.
 	ErrResourceNotFound = errors.New("Resource not found")
 
+	// ErrRateLimited will be used if the response from the api has 429 as HTTP status.
+	// Like ErrResourceNotFound, it is matched by the returned APIError with errors.Is.
+	ErrRateLimited = errors.New("Rate limited by the api")
+
+	// ErrServerError will be used if the response from the api has a 5xx HTTP status.
+	// Like ErrResourceNotFound, it is matched by the returned APIError with errors.Is.
+	ErrServerError = errors.New("Server error from the api")
+
 	// ErrNoResultSet will be used if no result set exists. Example if response is on first page
 	// and you call Prev() on the response, this error will be returned, as no previous result
 	// set exists.
 	ErrNoResultSet = errors.New("This result set does not exist")
 
+	// ErrInvalidPage will be used if a request asks for a page less than 1.
+	ErrInvalidPage = errors.New("Page must be 1 or greater")
+
+	// ErrInvalidPageSize will be used if a request asks for a page size the api
+	// does not accept, see MinPageSize and MaxPageSize.
+	ErrInvalidPageSize = errors.New("Page size must be between 1 and 50")
+
 	// ErrPaginationInfoMissing will be used if the api is returning an invalid url.
 	ErrPaginationInfoMissing = errors.New("Pagination info missing from returned url by api")
 )
+
+// APIError is returned by the client when the api responds with a non-2xx HTTP status.
+//
+// APIError can be compared with errors.Is against ErrResourceNotFound,
+// ErrRateLimited and ErrServerError, depending on the status code. Before the
+// client returned APIError, it returned those errors themselves, so code
+// comparing the error with == must be changed to use errors.Is.
+type APIError struct {
+	// The HTTP status code of the response.
+	StatusCode int
+
+	// The HTTP method of the request.
+	Method string
+
+	// The URL of the request.
+	URL string
+
+	// The headers of the response.
+	Header http.Header
+
+	// The beginning of the response body, at most 1024 bytes.
+	Body []byte
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.URL = resp.Request.URL.String()
+	}
+
+	return apiErr
+}
+
+// Error makes the APIError type implement the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports whether the APIError matches the given sentinel error.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrResourceNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= 500 && e.StatusCode <= 599
+	}
+
+	return false
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		status int
+		target error
+	}{
+		{http.StatusNotFound, ErrResourceNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrServerError},
+		{http.StatusServiceUnavailable, ErrServerError},
+		{http.StatusBadRequest, nil},
+	}
+	sentinels := []error{ErrResourceNotFound, ErrRateLimited, ErrServerError}
+
+	for _, test := range tests {
+		err := error(&APIError{StatusCode: test.status})
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == test.target) {
+				t.Errorf("status %d: errors.Is(err, %q) = %v", test.status, sentinel, got)
+			}
+		}
+
+		// The sentinel errors themselves no longer compare equal.
+		if err == test.target {
+			t.Errorf("status %d: APIError compares equal to %q", test.status, test.target)
+		}
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://www.anapioficeandfire.com/api/characters/0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{"Content-Type": {"text/plain"}},
+		Body:       io.NopCloser(strings.NewReader(strings.Repeat("x", 2*maxErrorBodySize))),
+		Request:    req,
+	}
+
+	apiErr := newAPIError(resp)
+	if len(apiErr.Body) != maxErrorBodySize {
+		t.Errorf("Body holds %d bytes, want %d", len(apiErr.Body), maxErrorBodySize)
+	}
+	if apiErr.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("Header = %v, want the response headers", apiErr.Header)
+	}
+
+	want := "GET http://www.anapioficeandfire.com/api/characters/0: 404 Not Found"
+	if apiErr.Error() != want {
+		t.Errorf("Error() = %q, want %q", apiErr.Error(), want)
+	}
+}
@@ -0,0 +1,540 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"errors"
+)
+
+// ExpandedCharacter is a Character together with the resources it refers to.
+// Only the relations requested when expanding are set.
+type ExpandedCharacter struct {
+	Character
+
+	// The father of this character.
+	Father *ExpandedCharacter
+
+	// The mother of this character.
+	Mother *ExpandedCharacter
+
+	// The spouse of this character.
+	Spouse *ExpandedCharacter
+
+	// The houses that this character is loyal to.
+	Allegiances []ExpandedHouse
+}
+
+// ExpandedHouse is a House together with the resources it refers to.
+// Only the relations requested when expanding are set.
+type ExpandedHouse struct {
+	House
+
+	// The current lord of this house.
+	CurrentLord *ExpandedCharacter
+
+	// The heir of this house.
+	Heir *ExpandedCharacter
+
+	// The house that this house answers to.
+	Overlord *ExpandedHouse
+
+	// The character that founded this house.
+	Founder *ExpandedCharacter
+
+	// The houses that was founded from this house.
+	CadetBranches []ExpandedHouse
+
+	// The characters that are sworn to this house.
+	SwornMembers []ExpandedCharacter
+}
+
+// ExpandedBook is a Book together with the resources it refers to.
+// Only the relations requested when expanding are set.
+type ExpandedBook struct {
+	Book
+
+	// The characters that has been in this book.
+	Characters []ExpandedCharacter
+
+	// The characters that has had a POV-chapter in this book.
+	PovCharacters []ExpandedCharacter
+}
+
+type relation int
+
+const (
+	relFather relation = iota
+	relMother
+	relSpouse
+	relAllegiances
+	relCurrentLord
+	relHeir
+	relOverlord
+	relFounder
+	relCadetBranches
+	relSwornMembers
+	relCharacters
+	relPovCharacters
+)
+
+// ExpandOption selects which relations are resolved when expanding
+// a resource, and how.
+type ExpandOption func(*expandOptions)
+
+type expandOptions struct {
+	relations map[relation]bool
+	depth     int
+	workers   int
+}
+
+func withRelation(rel relation) ExpandOption {
+	return func(o *expandOptions) {
+		o.relations[rel] = true
+	}
+}
+
+var (
+	// WithFather resolves the father of a character.
+	WithFather = withRelation(relFather)
+
+	// WithMother resolves the mother of a character.
+	WithMother = withRelation(relMother)
+
+	// WithSpouse resolves the spouse of a character.
+	WithSpouse = withRelation(relSpouse)
+
+	// WithAllegiances resolves the houses a character is loyal to.
+	WithAllegiances = withRelation(relAllegiances)
+
+	// WithCurrentLord resolves the current lord of a house.
+	WithCurrentLord = withRelation(relCurrentLord)
+
+	// WithHeir resolves the heir of a house.
+	WithHeir = withRelation(relHeir)
+
+	// WithOverlord resolves the house a house answers to.
+	WithOverlord = withRelation(relOverlord)
+
+	// WithFounder resolves the founder of a house.
+	WithFounder = withRelation(relFounder)
+
+	// WithCadetBranches resolves the houses founded from a house.
+	WithCadetBranches = withRelation(relCadetBranches)
+
+	// WithSwornMembers resolves the characters sworn to a house.
+	WithSwornMembers = withRelation(relSwornMembers)
+
+	// WithCharacters resolves the characters that has been in a book.
+	WithCharacters = withRelation(relCharacters)
+
+	// WithPovCharacters resolves the characters that has had a POV-chapter in a book.
+	WithPovCharacters = withRelation(relPovCharacters)
+)
+
+// WithDepth sets how many levels of relations are resolved. With the default
+// depth of 1 only the resources referred to by the expanded resource are
+// fetched, with a depth of 2 their relations are resolved as well, and so on.
+func WithDepth(depth int) ExpandOption {
+	return func(o *expandOptions) {
+		o.depth = depth
+	}
+}
+
+// WithExpandWorkers sets the number of concurrent requests used to fetch
+// the related resources. The default is DefaultWorkers.
+func WithExpandWorkers(workers int) ExpandOption {
+	return func(o *expandOptions) {
+		o.workers = workers
+	}
+}
+
+// ExpandCharacter fetches the resources the character refers to, as selected
+// by the options, e.g.
+//
+//	expanded, err := goiaf.ExpandCharacter(ctx, client, character, goiaf.WithFather, goiaf.WithAllegiances)
+//
+// Every resource is fetched at most once. A resource which is already being
+// expanded further up, e.g. the spouse of a character's spouse, is included
+// without its relations, so cycles are never followed. References to
+// resources which do not exist in the api are left empty.
+func ExpandCharacter(ctx context.Context, client Client, character Character, opts ...ExpandOption) (ExpandedCharacter, error) {
+	e := newExpander(ctx, client, opts)
+	if id, ok := character.ID(); ok {
+		e.characters[id] = character
+	}
+	return e.character(character, e.opts.depth)
+}
+
+// ExpandHouse fetches the resources the house refers to, as selected by the
+// options. See ExpandCharacter for details.
+func ExpandHouse(ctx context.Context, client Client, house House, opts ...ExpandOption) (ExpandedHouse, error) {
+	e := newExpander(ctx, client, opts)
+	if id, ok := house.ID(); ok {
+		e.houses[id] = house
+	}
+	return e.house(house, e.opts.depth)
+}
+
+// ExpandBook fetches the resources the book refers to, as selected by the
+// options. See ExpandCharacter for details.
+func ExpandBook(ctx context.Context, client Client, book Book, opts ...ExpandOption) (ExpandedBook, error) {
+	e := newExpander(ctx, client, opts)
+	return e.book(book, e.opts.depth)
+}
+
+// expander resolves relations, sharing the fetched resources between
+// all relations of one expansion.
+type expander struct {
+	ctx    context.Context
+	client Client
+	opts   expandOptions
+
+	characters map[CharacterID]Character
+	houses     map[HouseID]House
+
+	// notFoundCharacters and notFoundHouses hold the ids which were fetched
+	// but do not exist in the api, so they are not fetched again.
+	notFoundCharacters map[CharacterID]bool
+	notFoundHouses     map[HouseID]bool
+
+	// path holds the URLs of the resources currently being expanded.
+	path map[string]bool
+}
+
+func newExpander(ctx context.Context, client Client, opts []ExpandOption) *expander {
+	o := expandOptions{
+		relations: map[relation]bool{},
+		depth:     1,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &expander{
+		ctx:                ctx,
+		client:             client,
+		opts:               o,
+		characters:         map[CharacterID]Character{},
+		houses:             map[HouseID]House{},
+		notFoundCharacters: map[CharacterID]bool{},
+		notFoundHouses:     map[HouseID]bool{},
+		path:               map[string]bool{},
+	}
+}
+
+func (e *expander) has(rel relation) bool {
+	return e.opts.relations[rel]
+}
+
+// enter marks the resource as being expanded. It returns false if the
+// resource should not be expanded, because the depth is exhausted or
+// the resource is already being expanded.
+func (e *expander) enter(url string, depth int) bool {
+	if depth <= 0 || e.path[url] {
+		return false
+	}
+
+	e.path[url] = true
+	return true
+}
+
+func (e *expander) leave(url string) {
+	delete(e.path, url)
+}
+
+func (e *expander) character(character Character, depth int) (ExpandedCharacter, error) {
+	expanded := ExpandedCharacter{Character: character}
+	if !e.enter(character.URL, depth) {
+		return expanded, nil
+	}
+	defer e.leave(character.URL)
+
+	characterIDs := []CharacterID{}
+	if e.has(relFather) {
+		characterIDs = appendID(characterIDs, character.FatherID)
+	}
+	if e.has(relMother) {
+		characterIDs = appendID(characterIDs, character.MotherID)
+	}
+	if e.has(relSpouse) {
+		characterIDs = appendID(characterIDs, character.SpouseID)
+	}
+	houseIDs := []HouseID{}
+	if e.has(relAllegiances) {
+		houseIDs = character.AllegianceIds
+	}
+	if err := e.prefetch(characterIDs, houseIDs); err != nil {
+		return expanded, err
+	}
+
+	var err error
+	if e.has(relFather) {
+		if expanded.Father, err = e.characterRef(character.FatherID, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+	if e.has(relMother) {
+		if expanded.Mother, err = e.characterRef(character.MotherID, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+	if e.has(relSpouse) {
+		if expanded.Spouse, err = e.characterRef(character.SpouseID, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+	if e.has(relAllegiances) {
+		if expanded.Allegiances, err = e.houseRefs(character.AllegianceIds, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+
+	return expanded, nil
+}
+
+func (e *expander) house(house House, depth int) (ExpandedHouse, error) {
+	expanded := ExpandedHouse{House: house}
+	if !e.enter(house.URL, depth) {
+		return expanded, nil
+	}
+	defer e.leave(house.URL)
+
+	characterIDs := []CharacterID{}
+	if e.has(relCurrentLord) {
+		characterIDs = appendID(characterIDs, house.CurrentLordID)
+	}
+	if e.has(relHeir) {
+		characterIDs = appendID(characterIDs, house.HeirID)
+	}
+	if e.has(relFounder) {
+		characterIDs = appendID(characterIDs, house.FounderID)
+	}
+	if e.has(relSwornMembers) {
+		characterIDs = append(characterIDs, house.SwornMembersIds...)
+	}
+	houseIDs := []HouseID{}
+	if e.has(relOverlord) {
+		houseIDs = appendID(houseIDs, house.OverlordID)
+	}
+	if e.has(relCadetBranches) {
+		houseIDs = append(houseIDs, house.CadetBranchesIds...)
+	}
+	if err := e.prefetch(characterIDs, houseIDs); err != nil {
+		return expanded, err
+	}
+
+	var err error
+	if e.has(relCurrentLord) {
+		if expanded.CurrentLord, err = e.characterRef(house.CurrentLordID, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+	if e.has(relHeir) {
+		if expanded.Heir, err = e.characterRef(house.HeirID, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+	if e.has(relOverlord) {
+		if expanded.Overlord, err = e.houseRef(house.OverlordID, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+	if e.has(relFounder) {
+		if expanded.Founder, err = e.characterRef(house.FounderID, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+	if e.has(relCadetBranches) {
+		if expanded.CadetBranches, err = e.houseRefs(house.CadetBranchesIds, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+	if e.has(relSwornMembers) {
+		if expanded.SwornMembers, err = e.characterRefs(house.SwornMembersIds, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+
+	return expanded, nil
+}
+
+func (e *expander) book(book Book, depth int) (ExpandedBook, error) {
+	expanded := ExpandedBook{Book: book}
+	if !e.enter(book.URL, depth) {
+		return expanded, nil
+	}
+	defer e.leave(book.URL)
+
+	characterIDs := []CharacterID{}
+	if e.has(relCharacters) {
+		characterIDs = append(characterIDs, book.CharacterIds...)
+	}
+	if e.has(relPovCharacters) {
+		characterIDs = append(characterIDs, book.PovCharacterIds...)
+	}
+	if err := e.prefetch(characterIDs, nil); err != nil {
+		return expanded, err
+	}
+
+	var err error
+	if e.has(relCharacters) {
+		if expanded.Characters, err = e.characterRefs(book.CharacterIds, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+	if e.has(relPovCharacters) {
+		if expanded.PovCharacters, err = e.characterRefs(book.PovCharacterIds, depth-1); err != nil {
+			return expanded, err
+		}
+	}
+
+	return expanded, nil
+}
+
+func (e *expander) characterRef(id *CharacterID, depth int) (*ExpandedCharacter, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	character, ok := e.characters[*id]
+	if !ok {
+		return nil, nil
+	}
+
+	expanded, err := e.character(character, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &expanded, nil
+}
+
+func (e *expander) characterRefs(ids []CharacterID, depth int) ([]ExpandedCharacter, error) {
+	characters := []ExpandedCharacter{}
+	for _, id := range ids {
+		expanded, err := e.characterRef(&id, depth)
+		if err != nil {
+			return nil, err
+		}
+		if expanded != nil {
+			characters = append(characters, *expanded)
+		}
+	}
+
+	return characters, nil
+}
+
+func (e *expander) houseRef(id *HouseID, depth int) (*ExpandedHouse, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	house, ok := e.houses[*id]
+	if !ok {
+		return nil, nil
+	}
+
+	expanded, err := e.house(house, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &expanded, nil
+}
+
+func (e *expander) houseRefs(ids []HouseID, depth int) ([]ExpandedHouse, error) {
+	houses := []ExpandedHouse{}
+	for _, id := range ids {
+		expanded, err := e.houseRef(&id, depth)
+		if err != nil {
+			return nil, err
+		}
+		if expanded != nil {
+			houses = append(houses, *expanded)
+		}
+	}
+
+	return houses, nil
+}
+
+// prefetch fetches the characters and houses with the given ids which have
+// not been fetched yet. Ids which turn out not to exist are remembered, so
+// they cost a single request per expansion.
+func (e *expander) prefetch(characterIDs []CharacterID, houseIDs []HouseID) error {
+	missingCharacters := []CharacterID{}
+	for _, id := range characterIDs {
+		if _, ok := e.characters[id]; !ok && !e.notFoundCharacters[id] {
+			missingCharacters = append(missingCharacters, id)
+		}
+	}
+	if len(missingCharacters) > 0 {
+		characters, err := CharactersByID(e.ctx, e.client, missingCharacters, e.opts.workers)
+		if err := ignoreNotFound(err); err != nil {
+			return err
+		}
+		for _, character := range characters {
+			if id, ok := character.ID(); ok {
+				e.characters[id] = character
+			}
+		}
+		for _, id := range missingCharacters {
+			if _, ok := e.characters[id]; !ok {
+				e.notFoundCharacters[id] = true
+			}
+		}
+	}
+
+	missingHouses := []HouseID{}
+	for _, id := range houseIDs {
+		if _, ok := e.houses[id]; !ok && !e.notFoundHouses[id] {
+			missingHouses = append(missingHouses, id)
+		}
+	}
+	if len(missingHouses) > 0 {
+		houses, err := HousesByID(e.ctx, e.client, missingHouses, e.opts.workers)
+		if err := ignoreNotFound(err); err != nil {
+			return err
+		}
+		for _, house := range houses {
+			if id, ok := house.ID(); ok {
+				e.houses[id] = house
+			}
+		}
+		for _, id := range missingHouses {
+			if _, ok := e.houses[id]; !ok {
+				e.notFoundHouses[id] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// appendID appends the id to the ids, unless it is nil.
+func appendID[T any](ids []T, id *T) []T {
+	if id == nil {
+		return ids
+	}
+
+	return append(ids, *id)
+}
+
+// ignoreNotFound returns the error of a batch, unless all failed ids were
+// not found in the api.
+func ignoreNotFound(err error) error {
+	var batchErr BatchError
+	if !errors.As(err, &batchErr) {
+		return err
+	}
+
+	for _, idErr := range batchErr {
+		if !errors.Is(idErr, ErrResourceNotFound) {
+			return idErr
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/goiaftest"
+)
+
+// missingID is referred to by the expand fixtures, but does not exist.
+const missingID = 99
+
+func characterRef(id goiaf.CharacterID) *goiaf.CharacterID { return &id }
+
+func houseRef(id goiaf.HouseID) *goiaf.HouseID { return &id }
+
+// newExpandServer serves a small family:
+//
+//	Rickard (3), whose father does not exist
+//	  Eddard (1) + Catelyn (2)
+//	    Robb (4), whose spouse does not exist
+//
+// House Stark (1) is led by Eddard, its overlord does not exist, and House
+// Karstark (2) is its cadet branch. The book has all characters in it.
+func newExpandServer(t *testing.T) (*goiaftest.Server, goiaftest.Fixtures) {
+	t.Helper()
+
+	character := func(id int, name string) goiaf.Character {
+		return goiaf.Character{URL: fmt.Sprintf("%s/characters/%d", fixtureBaseURL, id), Name: name}
+	}
+	house := func(id int, name string) goiaf.House {
+		return goiaf.House{URL: fmt.Sprintf("%s/houses/%d", fixtureBaseURL, id), Name: name}
+	}
+
+	eddard, catelyn, rickard, robb := character(1, "Eddard Stark"), character(2, "Catelyn Tully"), character(3, "Rickard Stark"), character(4, "Robb Stark")
+	eddard.FatherID, eddard.SpouseID, eddard.AllegianceIds = characterRef(3), characterRef(2), []goiaf.HouseID{1}
+	catelyn.SpouseID = characterRef(1)
+	rickard.FatherID = characterRef(missingID)
+	robb.FatherID, robb.MotherID, robb.SpouseID = characterRef(1), characterRef(2), characterRef(missingID)
+
+	stark, karstark := house(1, "House Stark"), house(2, "House Karstark")
+	stark.CurrentLordID, stark.HeirID, stark.OverlordID = characterRef(1), characterRef(4), houseRef(missingID)
+	stark.CadetBranchesIds, stark.SwornMembersIds = []goiaf.HouseID{2}, []goiaf.CharacterID{1, 4}
+	karstark.OverlordID = houseRef(1)
+
+	book := goiaf.Book{
+		URL:             fixtureBaseURL + "/books/1",
+		Name:            "A Game of Thrones",
+		CharacterIds:    []goiaf.CharacterID{1, 2, 3, 4, missingID},
+		PovCharacterIds: []goiaf.CharacterID{1},
+	}
+
+	fixtures := goiaftest.Fixtures{
+		Books:      []goiaf.Book{book},
+		Characters: []goiaf.Character{eddard, catelyn, rickard, robb},
+		Houses:     []goiaf.House{stark, karstark},
+	}
+
+	server := goiaftest.NewServer(fixtures)
+	t.Cleanup(server.Close)
+
+	return server, fixtures
+}
+
+func TestExpandCharacterDepth(t *testing.T) {
+	server, fixtures := newExpandServer(t)
+	robb := fixtures.Characters[3]
+
+	expanded, err := goiaf.ExpandCharacter(context.Background(), server.Client(), robb, goiaf.WithFather, goiaf.WithMother)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expanded.Father == nil || expanded.Father.Name != "Eddard Stark" || expanded.Mother == nil || expanded.Mother.Name != "Catelyn Tully" {
+		t.Fatalf("expanded parents = %v and %v, want Eddard and Catelyn", expanded.Father, expanded.Mother)
+	}
+	if expanded.Father.Father != nil {
+		t.Errorf("grandfather = %v at depth 1, want nil", expanded.Father.Father)
+	}
+	if server.Requests() != 2 {
+		t.Errorf("server received %d requests, want 2", server.Requests())
+	}
+
+	expanded, err = goiaf.ExpandCharacter(context.Background(), server.Client(), robb, goiaf.WithFather, goiaf.WithDepth(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expanded.Father == nil || expanded.Father.Father == nil || expanded.Father.Father.Name != "Rickard Stark" {
+		t.Fatalf("grandfather not expanded at depth 3: %+v", expanded.Father)
+	}
+	if expanded.Father.Father.Father != nil {
+		t.Errorf("father of Rickard = %v, want nil as he does not exist", expanded.Father.Father.Father)
+	}
+}
+
+func TestExpandCharacterSpouseCycle(t *testing.T) {
+	server, fixtures := newExpandServer(t)
+
+	expanded, err := goiaf.ExpandCharacter(context.Background(), server.Client(), fixtures.Characters[0], goiaf.WithSpouse, goiaf.WithDepth(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spouse := expanded.Spouse
+	if spouse == nil || spouse.Name != "Catelyn Tully" {
+		t.Fatalf("spouse = %v, want Catelyn", spouse)
+	}
+	if spouse.Spouse == nil || spouse.Spouse.Name != "Eddard Stark" {
+		t.Fatalf("spouse of spouse = %v, want Eddard", spouse.Spouse)
+	}
+	if spouse.Spouse.Spouse != nil {
+		t.Errorf("Eddard is expanded again inside his own expansion")
+	}
+	if server.Requests() != 1 {
+		t.Errorf("server received %d requests, want 1 for Catelyn", server.Requests())
+	}
+}
+
+func TestExpandHouse(t *testing.T) {
+	server, fixtures := newExpandServer(t)
+
+	expanded, err := goiaf.ExpandHouse(context.Background(), server.Client(), fixtures.Houses[0],
+		goiaf.WithOverlord, goiaf.WithCadetBranches, goiaf.WithCurrentLord, goiaf.WithSwornMembers, goiaf.WithDepth(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expanded.Overlord != nil {
+		t.Errorf("overlord = %v, want nil as it does not exist", expanded.Overlord)
+	}
+	if expanded.CurrentLord == nil || expanded.CurrentLord.Name != "Eddard Stark" {
+		t.Errorf("current lord = %v, want Eddard", expanded.CurrentLord)
+	}
+	if len(expanded.SwornMembers) != 2 || expanded.SwornMembers[1].Name != "Robb Stark" {
+		t.Errorf("sworn members = %v, want Eddard and Robb", expanded.SwornMembers)
+	}
+	if len(expanded.CadetBranches) != 1 || expanded.CadetBranches[0].Name != "House Karstark" {
+		t.Fatalf("cadet branches = %v, want House Karstark", expanded.CadetBranches)
+	}
+
+	overlord := expanded.CadetBranches[0].Overlord
+	if overlord == nil || overlord.Name != "House Stark" || overlord.CadetBranches != nil {
+		t.Errorf("overlord of the cadet branch = %+v, want House Stark without its relations", overlord)
+	}
+
+	// Overlord 99, characters 1 and 4 and house 2; house 99 is not fetched
+	// again for the overlord of the cadet branch.
+	if server.Requests() != 4 {
+		t.Errorf("server received %d requests, want 4", server.Requests())
+	}
+}
+
+func TestExpandBookNotFoundFetchedOnce(t *testing.T) {
+	server, fixtures := newExpandServer(t)
+
+	expanded, err := goiaf.ExpandBook(context.Background(), server.Client(), fixtures.Books[0],
+		goiaf.WithCharacters, goiaf.WithPovCharacters, goiaf.WithFather, goiaf.WithSpouse, goiaf.WithDepth(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(expanded.Characters) != 4 {
+		t.Errorf("expanded %d characters, want the 4 which exist", len(expanded.Characters))
+	}
+	if len(expanded.PovCharacters) != 1 || expanded.PovCharacters[0].Spouse == nil {
+		t.Errorf("pov characters = %v, want Eddard with his spouse", expanded.PovCharacters)
+	}
+
+	// Characters 1 to 4 and 99 once, although Rickard refers to 99 as his
+	// father and Robb as his spouse.
+	if server.Requests() != 5 {
+		t.Errorf("server received %d requests, want 5", server.Requests())
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import "context"
+
+// FetchAllBooks returns all books matching the request. The first result set
+// is fetched to find the number of pages from its last link, the remaining
+// pages are then fetched concurrently by the given number of workers. The
+// books are returned in the order of the pages.
+//
+// FetchAllBooks stops at the first error or when the context is cancelled.
+// A nil request returns all books, and a workers value of zero or less
+// uses DefaultWorkers.
+func FetchAllBooks(ctx context.Context, client Client, request BookRequest, workers int) ([]Book, error) {
+	if request == nil {
+		request = NewBookRequest()
+	}
+
+	first, err := client.BooksContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := first.Last()
+	if err == ErrNoResultSet {
+		return first.Data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	firstPage := pageOf(request)
+	remaining := pageOf(last) - firstPage
+	if remaining < 0 {
+		remaining = 0
+	}
+	pages := make([][]Book, remaining)
+
+	err = parallel(ctx, len(pages), workers, func(ctx context.Context, i int) error {
+		response, err := client.BooksContext(ctx, request.Page(firstPage+i+1))
+		if err != nil {
+			return err
+		}
+
+		pages[i] = response.Data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	books := first.Data
+	for _, page := range pages {
+		books = append(books, page...)
+	}
+
+	return books, nil
+}
+
+// FetchAllCharacters returns all characters matching the request. The first result set
+// is fetched to find the number of pages from its last link, the remaining
+// pages are then fetched concurrently by the given number of workers. The
+// characters are returned in the order of the pages.
+//
+// FetchAllCharacters stops at the first error or when the context is cancelled.
+// A nil request returns all characters, and a workers value of zero or less
+// uses DefaultWorkers.
+func FetchAllCharacters(ctx context.Context, client Client, request CharacterRequest, workers int) ([]Character, error) {
+	if request == nil {
+		request = NewCharacterRequest()
+	}
+
+	first, err := client.CharactersContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := first.Last()
+	if err == ErrNoResultSet {
+		return first.Data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	firstPage := pageOf(request)
+	remaining := pageOf(last) - firstPage
+	if remaining < 0 {
+		remaining = 0
+	}
+	pages := make([][]Character, remaining)
+
+	err = parallel(ctx, len(pages), workers, func(ctx context.Context, i int) error {
+		response, err := client.CharactersContext(ctx, request.Page(firstPage+i+1))
+		if err != nil {
+			return err
+		}
+
+		pages[i] = response.Data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	characters := first.Data
+	for _, page := range pages {
+		characters = append(characters, page...)
+	}
+
+	return characters, nil
+}
+
+// FetchAllHouses returns all houses matching the request. The first result set
+// is fetched to find the number of pages from its last link, the remaining
+// pages are then fetched concurrently by the given number of workers. The
+// houses are returned in the order of the pages.
+//
+// FetchAllHouses stops at the first error or when the context is cancelled.
+// A nil request returns all houses, and a workers value of zero or less
+// uses DefaultWorkers.
+func FetchAllHouses(ctx context.Context, client Client, request HouseRequest, workers int) ([]House, error) {
+	if request == nil {
+		request = NewHouseRequest()
+	}
+
+	first, err := client.HousesContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := first.Last()
+	if err == ErrNoResultSet {
+		return first.Data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	firstPage := pageOf(request)
+	remaining := pageOf(last) - firstPage
+	if remaining < 0 {
+		remaining = 0
+	}
+	pages := make([][]House, remaining)
+
+	err = parallel(ctx, len(pages), workers, func(ctx context.Context, i int) error {
+		response, err := client.HousesContext(ctx, request.Page(firstPage+i+1))
+		if err != nil {
+			return err
+		}
+
+		pages[i] = response.Data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	houses := first.Data
+	for _, page := range pages {
+		houses = append(houses, page...)
+	}
+
+	return houses, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+// failingPageClient fails the requests for one page of characters.
+type failingPageClient struct {
+	goiaf.Client
+	page int
+	err  error
+}
+
+func (c failingPageClient) CharactersContext(ctx context.Context, request goiaf.CharacterRequest) (goiaf.CharacterResponse, error) {
+	if request.Convert().Get("page") == fmt.Sprint(c.page) {
+		return goiaf.CharacterResponse{}, c.err
+	}
+
+	return c.Client.CharactersContext(ctx, request)
+}
+
+func TestFetchAllCharacters(t *testing.T) {
+	fixtures := testFixtures()
+	client := goiaf.NewOfflineClient(fixtures.Books, fixtures.Characters, fixtures.Houses)
+
+	for _, workers := range []int{0, 1, 3, 10} {
+		characters, err := goiaf.FetchAllCharacters(context.Background(), client, goiaf.NewCharacterRequest().PageSize(4), workers)
+		if err != nil {
+			t.Fatalf("workers %d: %v", workers, err)
+		}
+
+		if len(characters) != len(fixtures.Characters) {
+			t.Fatalf("workers %d: fetched %d characters, want %d", workers, len(characters), len(fixtures.Characters))
+		}
+		for i, character := range characters {
+			if want := fmt.Sprintf("Character %d", i+1); character.Name != want {
+				t.Errorf("workers %d: characters[%d] = %q, want %q", workers, i, character.Name, want)
+			}
+		}
+	}
+}
+
+func TestFetchAllCharactersFromPage(t *testing.T) {
+	fixtures := testFixtures()
+	client := goiaf.NewOfflineClient(fixtures.Books, fixtures.Characters, fixtures.Houses)
+
+	characters, err := goiaf.FetchAllCharacters(context.Background(), client, goiaf.NewCharacterRequest().Page(5).PageSize(4), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(characters) != 9 || characters[0].Name != "Character 17" || characters[8].Name != "Character 25" {
+		t.Errorf("fetched %d characters from %q, want Character 17 to Character 25", len(characters), characters[0].Name)
+	}
+}
+
+func TestFetchAllCharactersError(t *testing.T) {
+	fixtures := testFixtures()
+	errFailed := errors.New("failed")
+	client := failingPageClient{
+		Client: goiaf.NewOfflineClient(fixtures.Books, fixtures.Characters, fixtures.Houses),
+		page:   3,
+		err:    errFailed,
+	}
+
+	characters, err := goiaf.FetchAllCharacters(context.Background(), client, goiaf.NewCharacterRequest().PageSize(4), 2)
+	if err != errFailed {
+		t.Errorf("FetchAllCharacters returned %v, want the error of page 3", err)
+	}
+	if characters != nil {
+		t.Errorf("FetchAllCharacters returned %d characters with an error, want none", len(characters))
+	}
+}
+
+func TestFetchAllBooksSinglePage(t *testing.T) {
+	server := newTestServer(t)
+
+	books, err := goiaf.FetchAllBooks(context.Background(), server.Client(), nil, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(books) != 3 || books[0].Name != "Book 1" || books[2].Name != "Book 3" {
+		t.Errorf("fetched %v, want Book 1 to Book 3", books)
+	}
+	if server.Requests() != 1 {
+		t.Errorf("server received %d requests, want 1 for a single page", server.Requests())
+	}
+}
+
+func TestFetchAllHousesOverHTTP(t *testing.T) {
+	server := newTestServer(t)
+
+	houses, err := goiaf.FetchAllHouses(context.Background(), server.Client(), goiaf.NewHouseRequest().PageSize(1), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, house := range houses {
+		if want := fmt.Sprintf("House %d", i+1); house.Name != want {
+			t.Errorf("houses[%d] = %q, want %q", i, house.Name, want)
+		}
+	}
+	if len(houses) != 4 || server.Requests() != 4 {
+		t.Errorf("fetched %d houses with %d requests, want 4 and 4", len(houses), server.Requests())
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/goiaftest"
+)
+
+const fixtureBaseURL = "http://www.anapioficeandfire.com/api"
+
+// testFixtures returns 3 books, 25 characters and 4 houses.
+func testFixtures() goiaftest.Fixtures {
+	fixtures := goiaftest.Fixtures{}
+
+	for i := 1; i <= 3; i++ {
+		fixtures.Books = append(fixtures.Books, goiaf.Book{
+			URL:  fmt.Sprintf("%s/books/%d", fixtureBaseURL, i),
+			Name: fmt.Sprintf("Book %d", i),
+		})
+	}
+	for i := 1; i <= 25; i++ {
+		fixtures.Characters = append(fixtures.Characters, goiaf.Character{
+			URL:    fmt.Sprintf("%s/characters/%d", fixtureBaseURL, i),
+			Name:   fmt.Sprintf("Character %d", i),
+			Gender: "Female",
+		})
+	}
+	for i := 1; i <= 4; i++ {
+		fixtures.Houses = append(fixtures.Houses, goiaf.House{
+			URL:  fmt.Sprintf("%s/houses/%d", fixtureBaseURL, i),
+			Name: fmt.Sprintf("House %d", i),
+		})
+	}
+
+	return fixtures
+}
+
+// newTestServer starts a goiaftest.Server with the test fixtures, which is
+// closed at the end of the test.
+func newTestServer(t *testing.T) *goiaftest.Server {
+	t.Helper()
+
+	server := goiaftest.NewServer(testFixtures())
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// failingTransport fails every request, to check that no request is sent.
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("unexpected request")
+}
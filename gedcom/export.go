@@ -0,0 +1,393 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package gedcom exports family graphs of characters as GEDCOM 5.5.1, which
+can be opened by genealogy software such as Gramps.
+
+Every character becomes an INDI record, and every pair of parents or
+spouses becomes a FAM record:
+
+	family, err := genealogy.FetchFamilyGraph(ctx, client)
+	checkErr(err)
+
+	err = gedcom.Write(os.Stdout, family, gedcom.WithRoot(583), gedcom.WithGenerations(3))
+	checkErr(err)
+
+Files written by the package can be read back with Parse.
+*/
+package gedcom
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/genealogy"
+)
+
+// submitterXRef is the cross-reference of the submitter record, which the
+// header of a 5.5.1 file must point to.
+const submitterXRef = "@U1@"
+
+// maxLineValue is the longest value written on a single line. GEDCOM limits
+// lines to 255 characters, longer values are continued with CONC.
+const maxLineValue = 200
+
+// Option is used to configure an export.
+type Option func(*options)
+
+type options struct {
+	root        *goiaf.CharacterID
+	generations int
+}
+
+// WithRoot limits the export to the family of the character: its ancestors
+// and descendants, and their spouses.
+func WithRoot(id goiaf.CharacterID) Option {
+	return func(o *options) {
+		o.root = &id
+	}
+}
+
+// WithGenerations limits the ancestors and descendants of the root to the
+// given number of generations. A value of zero or less, the default, exports
+// all generations. It has no effect without WithRoot.
+func WithGenerations(n int) Option {
+	return func(o *options) {
+		o.generations = n
+	}
+}
+
+// family is a FAM record. A zero id means the partner is unknown.
+type family struct {
+	husband  goiaf.CharacterID
+	wife     goiaf.CharacterID
+	children []goiaf.CharacterID
+	married  bool
+	xref     string
+}
+
+// Write writes the characters of the graph as a GEDCOM file to w. Only
+// characters which are part of the graph are written, links to other
+// characters are left out.
+func Write(w io.Writer, g *genealogy.FamilyGraph, opts ...Option) error {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	individuals := selectIndividuals(g, o)
+	families := buildFamilies(g, individuals)
+
+	childOf := map[goiaf.CharacterID][]string{}
+	spouseIn := map[goiaf.CharacterID][]string{}
+	for _, f := range families {
+		for _, child := range f.children {
+			childOf[child] = append(childOf[child], f.xref)
+		}
+		if f.husband != 0 {
+			spouseIn[f.husband] = append(spouseIn[f.husband], f.xref)
+		}
+		if f.wife != 0 {
+			spouseIn[f.wife] = append(spouseIn[f.wife], f.xref)
+		}
+	}
+
+	e := &encoder{w: bufio.NewWriter(w)}
+
+	e.line(0, "", "HEAD", "")
+	e.line(1, "", "SOUR", "goiaf")
+	e.line(1, "", "SUBM", submitterXRef)
+	e.line(1, "", "GEDC", "")
+	e.line(2, "", "VERS", "5.5.1")
+	e.line(2, "", "FORM", "LINEAGE-LINKED")
+	e.line(1, "", "CHAR", "UTF-8")
+
+	e.line(0, submitterXRef, "SUBM", "")
+	e.line(1, "", "NAME", "goiaf")
+
+	for _, id := range individuals {
+		character, _ := g.Character(id)
+
+		e.line(0, individualXRef(id), "INDI", "")
+		aliases := nonEmpty(character.Aliases)
+		if character.Name != "" || len(aliases) > 0 {
+			e.line(1, "", "NAME", formatName(character.Name))
+		}
+		for _, alias := range aliases {
+			e.line(2, "", "NICK", alias)
+		}
+		for _, title := range nonEmpty(character.Titles) {
+			e.line(1, "", "TITL", title)
+		}
+		if sex := formatSex(character.Gender); sex != "" {
+			e.line(1, "", "SEX", sex)
+		}
+		e.event("BIRT", character.Born)
+		e.event("DEAT", character.Died)
+		if character.URL != "" {
+			e.line(1, "", "_URL", character.URL)
+		}
+		for _, xref := range childOf[id] {
+			e.line(1, "", "FAMC", xref)
+		}
+		for _, xref := range spouseIn[id] {
+			e.line(1, "", "FAMS", xref)
+		}
+	}
+
+	for _, f := range families {
+		e.line(0, f.xref, "FAM", "")
+		if f.husband != 0 {
+			e.line(1, "", "HUSB", individualXRef(f.husband))
+		}
+		if f.wife != 0 {
+			e.line(1, "", "WIFE", individualXRef(f.wife))
+		}
+		if f.married {
+			e.line(1, "", "MARR", "Y")
+		}
+		for _, child := range f.children {
+			e.line(1, "", "CHIL", individualXRef(child))
+		}
+	}
+
+	e.line(0, "", "TRLR", "")
+
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.w.Flush()
+}
+
+// Marshal returns the characters of the graph as a GEDCOM file.
+func Marshal(g *genealogy.FamilyGraph, opts ...Option) ([]byte, error) {
+	var b strings.Builder
+	if err := Write(&b, g, opts...); err != nil {
+		return nil, err
+	}
+
+	return []byte(b.String()), nil
+}
+
+// selectIndividuals returns the ids of the characters to export, in
+// ascending order.
+func selectIndividuals(g *genealogy.FamilyGraph, o *options) []goiaf.CharacterID {
+	if o.root == nil {
+		return g.Characters()
+	}
+
+	selected := map[goiaf.CharacterID]bool{*o.root: true}
+	for _, id := range g.Ancestors(*o.root, o.generations) {
+		selected[id] = true
+	}
+	for _, id := range g.Descendants(*o.root, o.generations) {
+		selected[id] = true
+	}
+	for id := range selected {
+		for _, spouse := range g.Spouses(id) {
+			selected[spouse] = true
+		}
+	}
+
+	ids := []goiaf.CharacterID{}
+	for id := range selected {
+		if _, ok := g.Character(id); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+// buildFamilies groups the individuals into families, first by their
+// parents and then by their spouses.
+func buildFamilies(g *genealogy.FamilyGraph, individuals []goiaf.CharacterID) []*family {
+	included := map[goiaf.CharacterID]bool{}
+	for _, id := range individuals {
+		included[id] = true
+	}
+
+	type key struct{ husband, wife goiaf.CharacterID }
+	byKey := map[key]*family{}
+	families := []*family{}
+
+	get := func(husband, wife goiaf.CharacterID) *family {
+		if f, ok := byKey[key{husband, wife}]; ok {
+			return f
+		}
+		if f, ok := byKey[key{wife, husband}]; ok && husband != 0 && wife != 0 {
+			return f
+		}
+
+		f := &family{husband: husband, wife: wife}
+		byKey[key{husband, wife}] = f
+		families = append(families, f)
+		return f
+	}
+
+	for _, id := range individuals {
+		var father, mother goiaf.CharacterID
+		if parent, ok := g.Father(id); ok && included[parent] {
+			father = parent
+		}
+		if parent, ok := g.Mother(id); ok && included[parent] {
+			mother = parent
+		}
+		if father == 0 && mother == 0 {
+			continue
+		}
+
+		f := get(father, mother)
+		f.children = append(f.children, id)
+	}
+
+	for _, id := range individuals {
+		for _, spouse := range g.Spouses(id) {
+			if spouse < id || !included[spouse] {
+				continue
+			}
+
+			husband, wife := id, spouse
+			if isFemale(g, id) && !isFemale(g, spouse) {
+				husband, wife = spouse, id
+			}
+			get(husband, wife).married = true
+		}
+	}
+
+	sort.SliceStable(families, func(i, j int) bool {
+		if families[i].husband != families[j].husband {
+			return families[i].husband < families[j].husband
+		}
+		return families[i].wife < families[j].wife
+	})
+	for i, f := range families {
+		f.xref = fmt.Sprintf("@F%d@", i+1)
+	}
+
+	return families
+}
+
+func isFemale(g *genealogy.FamilyGraph, id goiaf.CharacterID) bool {
+	character, _ := g.Character(id)
+	return character.Gender == "Female"
+}
+
+func individualXRef(id goiaf.CharacterID) string {
+	return "@I" + id.String() + "@"
+}
+
+// formatName marks the last word of the name as surname, as in "Eddard /Stark/".
+func formatName(name string) string {
+	i := strings.LastIndex(name, " ")
+	if i <= 0 || i == len(name)-1 || isRomanNumeral(name[i+1:]) {
+		return name
+	}
+
+	return name[:i] + " /" + name[i+1:] + "/"
+}
+
+func isRomanNumeral(s string) bool {
+	return strings.Trim(s, "IVXLC") == ""
+}
+
+// nonEmpty returns the values which are not empty. The api uses a single
+// empty string for a character without aliases or titles.
+func nonEmpty(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+func formatSex(gender string) string {
+	switch gender {
+	case "":
+		return ""
+	case "Male":
+		return "M"
+	case "Female":
+		return "F"
+	}
+
+	return "U"
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// event writes an event with the text as note, if the text is known.
+func (e *encoder) event(tag, text string) {
+	if text == "" {
+		return
+	}
+
+	e.line(1, "", tag, "")
+	e.line(2, "", "NOTE", text)
+}
+
+// line writes a GEDCOM line. Values containing line breaks are continued
+// with CONT, and long values with CONC.
+func (e *encoder) line(level int, xref, tag, value string) {
+	for i, text := range strings.Split(value, "\n") {
+		head, rest := splitValue(text)
+		if i == 0 {
+			e.write(level, xref, tag, head)
+		} else {
+			e.write(level+1, "", "CONT", head)
+		}
+
+		for rest != "" {
+			head, rest = splitValue(rest)
+			e.write(level+1, "", "CONC", head)
+		}
+	}
+}
+
+func (e *encoder) write(level int, xref, tag, value string) {
+	if e.err != nil {
+		return
+	}
+
+	parts := []string{fmt.Sprint(level)}
+	if xref != "" {
+		parts = append(parts, xref)
+	}
+	parts = append(parts, tag)
+	if value != "" {
+		parts = append(parts, value)
+	}
+
+	_, e.err = e.w.WriteString(strings.Join(parts, " ") + "\n")
+}
+
+// splitValue splits off the first maxLineValue bytes of the value, without
+// splitting a character or leaving a space at either side of the split.
+func splitValue(value string) (string, string) {
+	if len(value) <= maxLineValue {
+		return value, ""
+	}
+
+	for i := maxLineValue; i > 1; i-- {
+		if utf8.RuneStart(value[i]) && value[i] != ' ' && value[i-1] != ' ' {
+			return value[:i], value[i:]
+		}
+	}
+
+	return value, ""
+}
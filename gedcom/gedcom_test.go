@@ -0,0 +1,198 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gedcom
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/genealogy"
+	"github.com/mattiaspernhult/goiaf/internal/testfixture"
+)
+
+// starks returns the family of testfixture.Starks, with aliases, titles,
+// dates and long values added to some of them.
+func starks() []goiaf.Character {
+	characters := testfixture.Starks()
+
+	for i := range characters {
+		switch id, _ := characters[i].ID(); id {
+		case 3:
+			characters[i].Aliases = []string{"Ned", "The Quiet Wolf"}
+			characters[i].Titles = []string{"Lord of Winterfell", "Hand of the King"}
+			characters[i].Born = "In 263 AC, at Winterfell"
+			characters[i].Died = "In 299 AC, at Great Sept of Baelor in King's Landing"
+		case 7:
+			characters[i].Aliases = []string{strings.Repeat("A girl has no name. ", 30)}
+		case 8:
+			characters[i].Died = "Unknown\nPerhaps beyond the Wall"
+		}
+	}
+
+	return characters
+}
+
+func roundTrip(t *testing.T, g *genealogy.FamilyGraph, opts ...Option) *genealogy.FamilyGraph {
+	t.Helper()
+
+	b, err := Marshal(g, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	characters, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Parse failed: %v\n%s", err, b)
+	}
+
+	return genealogy.NewFamilyGraph(characters)
+}
+
+func assertSameFamily(t *testing.T, want, got *genealogy.FamilyGraph) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got.Characters(), want.Characters()) {
+		t.Fatalf("Characters() = %v, want %v", got.Characters(), want.Characters())
+	}
+
+	for _, id := range want.Characters() {
+		w, _ := want.Character(id)
+		g, _ := got.Character(id)
+
+		if g.Name != w.Name || g.Born != w.Born || g.Died != w.Died || g.URL != w.URL {
+			t.Errorf("character %d = %+v, want %+v", id, g, w)
+		}
+		if !reflect.DeepEqual(g.Aliases, w.Aliases) || !reflect.DeepEqual(g.Titles, w.Titles) {
+			t.Errorf("character %d has aliases %q and titles %q, want %q and %q", id, g.Aliases, g.Titles, w.Aliases, w.Titles)
+		}
+		if w.Gender != "" && g.Gender != w.Gender {
+			t.Errorf("character %d has gender %q, want %q", id, g.Gender, w.Gender)
+		}
+		if !reflect.DeepEqual(got.Parents(id), want.Parents(id)) {
+			t.Errorf("Parents(%d) = %v, want %v", id, got.Parents(id), want.Parents(id))
+		}
+		if !reflect.DeepEqual(got.Spouses(id), want.Spouses(id)) {
+			t.Errorf("Spouses(%d) = %v, want %v", id, got.Spouses(id), want.Spouses(id))
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := genealogy.NewFamilyGraph(starks())
+	assertSameFamily(t, g, roundTrip(t, g))
+}
+
+func TestRoundTripWithRoot(t *testing.T) {
+	g := genealogy.NewFamilyGraph(starks())
+
+	// One generation around Robb: his parents, his son and their spouses.
+	got := roundTrip(t, g, WithRoot(6), WithGenerations(1))
+
+	var kept []goiaf.Character
+	for _, id := range []goiaf.CharacterID{3, 4, 6, 11} {
+		character, _ := g.Character(id)
+		if id == 3 {
+			// The parents of Eddard are not exported.
+			character.FatherID, character.MotherID = nil, nil
+		}
+		kept = append(kept, character)
+	}
+	assertSameFamily(t, genealogy.NewFamilyGraph(kept), got)
+
+	// Without a limit, all ancestors and descendants of Robb are exported,
+	// but not his siblings.
+	got = roundTrip(t, g, WithRoot(6))
+	if want := []goiaf.CharacterID{1, 2, 3, 4, 6, 11}; !reflect.DeepEqual(got.Characters(), want) {
+		t.Errorf("Characters() = %v, want %v", got.Characters(), want)
+	}
+}
+
+func TestWriteStructure(t *testing.T) {
+	b, err := Marshal(genealogy.NewFamilyGraph(starks()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+
+	for _, want := range []string{"0 HEAD", "1 SUBM @U1@", "2 VERS 5.5.1", "0 @U1@ SUBM", "1 NAME Eddard /Stark/", "2 NICK The Quiet Wolf", "1 NAME Hodor", "1 SEX M", "2 NOTE In 263 AC, at Winterfell", "3 CONT Perhaps beyond the Wall"} {
+		found := false
+		for _, line := range lines {
+			found = found || line == want
+		}
+		if !found {
+			t.Errorf("output has no line %q", want)
+		}
+	}
+	if lines[len(lines)-1] != "0 TRLR" {
+		t.Errorf("last line = %q, want 0 TRLR", lines[len(lines)-1])
+	}
+
+	for _, line := range lines {
+		if len(line) > 255 {
+			t.Errorf("line of %d characters is longer than 255", len(line))
+		}
+		if strings.Contains(line, " CONC  ") {
+			t.Errorf("CONC line starts with a space: %q", line)
+		}
+	}
+}
+
+func TestWriteSkipsEmptyAliasesAndTitles(t *testing.T) {
+	characters := []goiaf.Character{
+		testfixture.Character(1, "Hodor", "Male", 0, 0, 0),
+		testfixture.Character(2, "", "Male", 0, 0, 0),
+	}
+	characters[0].Aliases = []string{""}
+	characters[0].Titles = []string{""}
+	characters[1].Aliases = []string{"", "The Ghost of High Heart's friend"}
+
+	b, err := Marshal(genealogy.NewFamilyGraph(characters))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "0 @I1@ INDI\n1 NAME Hodor\n1 SEX M\n"
+	if !strings.Contains(string(b), want) {
+		t.Errorf("output has no record %q:\n%s", want, b)
+	}
+	want = "0 @I2@ INDI\n1 NAME\n2 NICK The Ghost of High Heart's friend\n1 SEX M\n"
+	if !strings.Contains(string(b), want) {
+		t.Errorf("output has no record %q:\n%s", want, b)
+	}
+
+	parsed, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed[0].Aliases) != 0 || len(parsed[0].Titles) != 0 {
+		t.Errorf("Hodor has aliases %q and titles %q, want none", parsed[0].Aliases, parsed[0].Titles)
+	}
+	if want := []string{"The Ghost of High Heart's friend"}; parsed[1].Name != "" || !reflect.DeepEqual(parsed[1].Aliases, want) {
+		t.Errorf("character 2 has name %q and aliases %q, want no name and %q", parsed[1].Name, parsed[1].Aliases, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  error
+	}{
+		{"no header", "0 TRLR\n", ErrHeaderMissing},
+		{"no trailer", "0 HEAD\n", ErrTrailerMissing},
+		{"bad level", "0 HEAD\nx NAME\n0 TRLR\n", nil},
+		{"skipped level", "0 HEAD\n2 VERS 5.5.1\n0 TRLR\n", nil},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.text))
+		if err == nil || (test.err != nil && err != test.err) {
+			t.Errorf("%s: Parse returned %v, want %v", test.name, err, test.err)
+		}
+	}
+}
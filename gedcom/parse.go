@@ -0,0 +1,262 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gedcom
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+var (
+	// ErrHeaderMissing will be used if a file does not start with a HEAD record.
+	ErrHeaderMissing = errors.New("GEDCOM header missing")
+
+	// ErrTrailerMissing will be used if a file does not end with a TRLR record.
+	ErrTrailerMissing = errors.New("GEDCOM trailer missing")
+)
+
+// node is a line of a GEDCOM file together with its subordinate lines.
+type node struct {
+	level    int
+	xref     string
+	tag      string
+	value    string
+	children []*node
+}
+
+// child returns the first subordinate line with the tag, or nil.
+func (n *node) child(tag string) *node {
+	for _, child := range n.children {
+		if child.tag == tag {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// values returns the values of all subordinate lines with the tag.
+func (n *node) values(tag string) []string {
+	values := []string{}
+	for _, child := range n.children {
+		if child.tag == tag {
+			values = append(values, child.value)
+		}
+	}
+
+	return values
+}
+
+// Parse reads a GEDCOM file written by Write and returns its characters,
+// in the order of the file. Parent links are taken from the families the
+// characters are children of, and spouse links from the married families
+// they are partners in, so the characters build the same FamilyGraph as
+// the one which was written.
+func Parse(r io.Reader) ([]goiaf.Character, error) {
+	records, err := parseRecords(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 || records[0].tag != "HEAD" {
+		return nil, ErrHeaderMissing
+	}
+	if records[len(records)-1].tag != "TRLR" {
+		return nil, ErrTrailerMissing
+	}
+
+	characters := []goiaf.Character{}
+	index := map[string]int{}
+	families := []*node{}
+
+	for _, record := range records {
+		switch record.tag {
+		case "INDI":
+			index[record.xref] = len(characters)
+			characters = append(characters, parseIndividual(record))
+		case "FAM":
+			families = append(families, record)
+		}
+	}
+
+	id := func(xref string) *goiaf.CharacterID {
+		i, ok := index[xref]
+		if !ok {
+			return nil
+		}
+		id, ok := characters[i].ID()
+		if !ok {
+			return nil
+		}
+		return &id
+	}
+
+	for _, f := range families {
+		husband, wife := "", ""
+		if n := f.child("HUSB"); n != nil {
+			husband = n.value
+		}
+		if n := f.child("WIFE"); n != nil {
+			wife = n.value
+		}
+
+		for _, child := range f.values("CHIL") {
+			i, ok := index[child]
+			if !ok {
+				continue
+			}
+			characters[i].FatherID = id(husband)
+			characters[i].MotherID = id(wife)
+		}
+
+		if f.child("MARR") == nil {
+			continue
+		}
+		if i, ok := index[husband]; ok && characters[i].SpouseID == nil {
+			characters[i].SpouseID = id(wife)
+		}
+		if i, ok := index[wife]; ok && characters[i].SpouseID == nil {
+			characters[i].SpouseID = id(husband)
+		}
+	}
+
+	return characters, nil
+}
+
+func parseIndividual(record *node) goiaf.Character {
+	character := goiaf.Character{
+		Titles:  record.values("TITL"),
+		Aliases: []string{},
+	}
+
+	if n := record.child("NAME"); n != nil {
+		character.Name = parseName(n.value)
+		character.Aliases = n.values("NICK")
+	}
+	if n := record.child("SEX"); n != nil {
+		character.Gender = parseSex(n.value)
+	}
+	if n := record.child("BIRT"); n != nil {
+		character.Born = strings.Join(n.values("NOTE"), "\n")
+	}
+	if n := record.child("DEAT"); n != nil {
+		character.Died = strings.Join(n.values("NOTE"), "\n")
+	}
+	if n := record.child("_URL"); n != nil {
+		character.URL = n.value
+	}
+
+	return character
+}
+
+// parseName removes the slashes around the surname of a name.
+func parseName(name string) string {
+	i := strings.Index(name, "/")
+	j := strings.LastIndex(name, "/")
+	if i < 0 || i == j {
+		return name
+	}
+
+	return strings.TrimSpace(name[:i] + name[i+1:j] + name[j+1:])
+}
+
+func parseSex(sex string) string {
+	switch sex {
+	case "M":
+		return "Male"
+	case "F":
+		return "Female"
+	}
+
+	return "Unknown"
+}
+
+// parseRecords reads the lines of a GEDCOM file into a tree of records,
+// joining CONT and CONC lines with the value they continue.
+func parseRecords(r io.Reader) ([]*node, error) {
+	records := []*node{}
+	stack := []*node{}
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if number == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		n, err := parseLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid GEDCOM line %d: %v", number, err)
+		}
+		if n.level > len(stack) {
+			return nil, fmt.Errorf("Invalid GEDCOM line %d: level %d follows level %d", number, n.level, len(stack)-1)
+		}
+		stack = stack[:n.level]
+
+		switch {
+		case n.level == 0:
+			records = append(records, n)
+		case n.tag == "CONT":
+			stack[n.level-1].value += "\n" + n.value
+			continue
+		case n.tag == "CONC":
+			stack[n.level-1].value += n.value
+			continue
+		default:
+			parent := stack[n.level-1]
+			parent.children = append(parent.children, n)
+		}
+
+		stack = append(stack, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// parseLine parses a line of the form "level [@xref@] tag [value]".
+func parseLine(text string) (*node, error) {
+	fields := strings.SplitN(strings.TrimLeft(text, " "), " ", 2)
+
+	level, err := strconv.Atoi(fields[0])
+	if err != nil || level < 0 {
+		return nil, fmt.Errorf("invalid level %q", fields[0])
+	}
+	if len(fields) < 2 || fields[1] == "" {
+		return nil, fmt.Errorf("missing tag")
+	}
+
+	n := &node{level: level}
+	rest := fields[1]
+	if strings.HasPrefix(rest, "@") {
+		fields = strings.SplitN(rest, " ", 2)
+		if len(fields) < 2 || len(fields[0]) < 3 || !strings.HasSuffix(fields[0], "@") {
+			return nil, fmt.Errorf("invalid cross-reference %q", fields[0])
+		}
+		n.xref, rest = fields[0], fields[1]
+	}
+
+	fields = strings.SplitN(rest, " ", 2)
+	n.tag = fields[0]
+	if n.tag == "" {
+		return nil, fmt.Errorf("missing tag")
+	}
+	if len(fields) == 2 {
+		n.value = fields[1]
+	}
+
+	return n, nil
+}
@@ -0,0 +1,269 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package genealogy builds family and feudal structures from the resources of
+An API Of Ice And Fire.
+
+A FamilyGraph links characters through their FatherID, MotherID and SpouseID,
+children are found by inverting the parent links:
+
+	characters, err := goiaf.FetchAllCharacters(ctx, client, nil, 0)
+	checkErr(err)
+
+	family := genealogy.NewFamilyGraph(characters)
+	for _, id := range family.Ancestors(583, 2) {
+		character, _ := family.Character(id)
+		fmt.Println(character.Name)
+	}
+*/
+package genealogy
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+// FamilyGraph is a directed graph of parent, child and spouse links between
+// characters. Links to characters which are not part of the graph are kept,
+// so ids returned by the graph may not resolve to a character.
+type FamilyGraph struct {
+	characters map[goiaf.CharacterID]goiaf.Character
+
+	fathers  map[goiaf.CharacterID]goiaf.CharacterID
+	mothers  map[goiaf.CharacterID]goiaf.CharacterID
+	children map[goiaf.CharacterID][]goiaf.CharacterID
+	spouses  map[goiaf.CharacterID][]goiaf.CharacterID
+}
+
+// NewFamilyGraph builds a FamilyGraph from the characters. Characters are
+// identified by their ID method, characters without a valid id are left out.
+func NewFamilyGraph(characters []goiaf.Character) *FamilyGraph {
+	g := &FamilyGraph{
+		characters: map[goiaf.CharacterID]goiaf.Character{},
+		fathers:    map[goiaf.CharacterID]goiaf.CharacterID{},
+		mothers:    map[goiaf.CharacterID]goiaf.CharacterID{},
+		children:   map[goiaf.CharacterID][]goiaf.CharacterID{},
+		spouses:    map[goiaf.CharacterID][]goiaf.CharacterID{},
+	}
+
+	for _, character := range characters {
+		if id, ok := character.ID(); ok {
+			g.characters[id] = character
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(g.characters)) {
+		character := g.characters[id]
+
+		if character.FatherID != nil {
+			g.fathers[id] = *character.FatherID
+			g.children[*character.FatherID] = appendUnique(g.children[*character.FatherID], id)
+		}
+		if character.MotherID != nil {
+			g.mothers[id] = *character.MotherID
+			g.children[*character.MotherID] = appendUnique(g.children[*character.MotherID], id)
+		}
+		if character.SpouseID != nil {
+			g.spouses[id] = appendUnique(g.spouses[id], *character.SpouseID)
+			g.spouses[*character.SpouseID] = appendUnique(g.spouses[*character.SpouseID], id)
+		}
+	}
+
+	return g
+}
+
+// FetchFamilyGraph fetches all characters through the client and builds
+// a FamilyGraph from them.
+func FetchFamilyGraph(ctx context.Context, client goiaf.Client) (*FamilyGraph, error) {
+	characters, err := goiaf.FetchAllCharacters(ctx, client, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFamilyGraph(characters), nil
+}
+
+// Character returns the character with the given id, and whether it is part
+// of the graph.
+func (g *FamilyGraph) Character(id goiaf.CharacterID) (goiaf.Character, bool) {
+	character, ok := g.characters[id]
+	return character, ok
+}
+
+// Characters returns the ids of all characters of the graph, in ascending order.
+func (g *FamilyGraph) Characters() []goiaf.CharacterID {
+	return slices.Sorted(maps.Keys(g.characters))
+}
+
+// Father returns the id of the father of the character, and whether it is known.
+func (g *FamilyGraph) Father(id goiaf.CharacterID) (goiaf.CharacterID, bool) {
+	father, ok := g.fathers[id]
+	return father, ok
+}
+
+// Mother returns the id of the mother of the character, and whether it is known.
+func (g *FamilyGraph) Mother(id goiaf.CharacterID) (goiaf.CharacterID, bool) {
+	mother, ok := g.mothers[id]
+	return mother, ok
+}
+
+// Parents returns the ids of the known parents of the character, father first.
+func (g *FamilyGraph) Parents(id goiaf.CharacterID) []goiaf.CharacterID {
+	parents := []goiaf.CharacterID{}
+	if father, ok := g.fathers[id]; ok {
+		parents = append(parents, father)
+	}
+	if mother, ok := g.mothers[id]; ok {
+		parents = append(parents, mother)
+	}
+
+	return parents
+}
+
+// Children returns the ids of the characters which have the character as
+// father or mother, in ascending order.
+func (g *FamilyGraph) Children(id goiaf.CharacterID) []goiaf.CharacterID {
+	return append([]goiaf.CharacterID{}, g.children[id]...)
+}
+
+// Spouses returns the ids of the spouses of the character. A spouse link
+// in either direction counts.
+func (g *FamilyGraph) Spouses(id goiaf.CharacterID) []goiaf.CharacterID {
+	return append([]goiaf.CharacterID{}, g.spouses[id]...)
+}
+
+// Ancestors returns the ids of the ancestors of the character up to the given
+// number of generations, ordered by generation. A depth of zero or less
+// returns all ancestors.
+func (g *FamilyGraph) Ancestors(id goiaf.CharacterID, depth int) []goiaf.CharacterID {
+	return g.walk(id, depth, g.Parents)
+}
+
+// Descendants returns the ids of the descendants of the character up to the
+// given number of generations, ordered by generation. A depth of zero or less
+// returns all descendants.
+func (g *FamilyGraph) Descendants(id goiaf.CharacterID, depth int) []goiaf.CharacterID {
+	return g.walk(id, depth, g.Children)
+}
+
+// walk does a breadth first search from the character, following next.
+func (g *FamilyGraph) walk(id goiaf.CharacterID, depth int, next func(goiaf.CharacterID) []goiaf.CharacterID) []goiaf.CharacterID {
+	visited := map[goiaf.CharacterID]bool{id: true}
+	result := []goiaf.CharacterID{}
+
+	generation := []goiaf.CharacterID{id}
+	for level := 1; len(generation) > 0 && (depth <= 0 || level <= depth); level++ {
+		nextGeneration := []goiaf.CharacterID{}
+		for _, current := range generation {
+			for _, related := range next(current) {
+				if !visited[related] {
+					visited[related] = true
+					nextGeneration = append(nextGeneration, related)
+				}
+			}
+		}
+
+		result = append(result, nextGeneration...)
+		generation = nextGeneration
+	}
+
+	return result
+}
+
+// Siblings returns the ids of the full siblings of the character, which are
+// the characters with the same known father and the same known mother.
+func (g *FamilyGraph) Siblings(id goiaf.CharacterID) []goiaf.CharacterID {
+	siblings := []goiaf.CharacterID{}
+	for _, other := range g.sharingParent(id) {
+		if g.sharedParents(id, other) == 2 {
+			siblings = append(siblings, other)
+		}
+	}
+
+	return siblings
+}
+
+// HalfSiblings returns the ids of the half siblings of the character, which
+// are the characters sharing exactly one known parent with it.
+func (g *FamilyGraph) HalfSiblings(id goiaf.CharacterID) []goiaf.CharacterID {
+	siblings := []goiaf.CharacterID{}
+	for _, other := range g.sharingParent(id) {
+		if g.sharedParents(id, other) == 1 {
+			siblings = append(siblings, other)
+		}
+	}
+
+	return siblings
+}
+
+// Cousins returns the ids of the first cousins of the character, which are
+// the characters sharing a grandparent but not a parent with it.
+func (g *FamilyGraph) Cousins(id goiaf.CharacterID) []goiaf.CharacterID {
+	exclude := map[goiaf.CharacterID]bool{id: true}
+	for _, sibling := range g.sharingParent(id) {
+		exclude[sibling] = true
+	}
+
+	cousins := map[goiaf.CharacterID]bool{}
+	for _, parent := range g.Parents(id) {
+		for _, grandparent := range g.Parents(parent) {
+			for _, uncle := range g.children[grandparent] {
+				if uncle == parent {
+					continue
+				}
+				for _, cousin := range g.children[uncle] {
+					if !exclude[cousin] {
+						cousins[cousin] = true
+					}
+				}
+			}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(cousins))
+}
+
+// sharingParent returns the ids of the characters sharing at least one
+// parent with the character, in ascending order.
+func (g *FamilyGraph) sharingParent(id goiaf.CharacterID) []goiaf.CharacterID {
+	others := map[goiaf.CharacterID]bool{}
+	for _, parent := range g.Parents(id) {
+		for _, child := range g.children[parent] {
+			if child != id {
+				others[child] = true
+			}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(others))
+}
+
+func (g *FamilyGraph) sharedParents(a, b goiaf.CharacterID) int {
+	shared := 0
+	if father, ok := g.fathers[a]; ok && father == g.fathers[b] {
+		if _, ok := g.fathers[b]; ok {
+			shared++
+		}
+	}
+	if mother, ok := g.mothers[a]; ok && mother == g.mothers[b] {
+		if _, ok := g.mothers[b]; ok {
+			shared++
+		}
+	}
+
+	return shared
+}
+
+// appendUnique appends the id unless the ids already contain it.
+func appendUnique[T comparable](ids []T, id T) []T {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+
+	return append(ids, id)
+}
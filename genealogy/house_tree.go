@@ -0,0 +1,356 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package genealogy
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+// WarningKind is the kind of inconsistency described by a Warning.
+type WarningKind int
+
+const (
+	// WarningMissingHouse is used if a house refers to a house which is
+	// not part of the tree.
+	WarningMissingHouse WarningKind = iota
+
+	// WarningFealtyCycle is used if following the overlords of a house
+	// leads back to the house.
+	WarningFealtyCycle
+
+	// WarningCadetCycle is used if following the cadet branches of a house
+	// leads back to the house.
+	WarningCadetCycle
+
+	// WarningMultipleParentHouses is used if a house is listed as cadet
+	// branch of more than one house.
+	WarningMultipleParentHouses
+
+	// WarningCadetOverlordMismatch is used if the overlord of a cadet branch
+	// is neither the house it was founded from nor one of that house's
+	// overlords.
+	WarningCadetOverlordMismatch
+)
+
+// String makes the WarningKind type implement the fmt.Stringer interface.
+func (k WarningKind) String() string {
+	switch k {
+	case WarningMissingHouse:
+		return "missing house"
+	case WarningFealtyCycle:
+		return "fealty cycle"
+	case WarningCadetCycle:
+		return "cadet cycle"
+	case WarningMultipleParentHouses:
+		return "multiple parent houses"
+	case WarningCadetOverlordMismatch:
+		return "cadet overlord mismatch"
+	}
+
+	return "unknown"
+}
+
+// Warning describes an inconsistency found while building a HouseTree.
+type Warning struct {
+	Kind WarningKind
+
+	// The house the warning is about.
+	House goiaf.HouseID
+
+	// The other houses involved, e.g. the houses of a cycle or the
+	// missing house.
+	Related []goiaf.HouseID
+
+	// A human readable description of the warning.
+	Message string
+}
+
+// String makes the Warning type implement the fmt.Stringer interface.
+func (w Warning) String() string {
+	return w.Message
+}
+
+// HouseTree is the feudal hierarchy of houses, built from their OverlordID
+// and CadetBranchesIds. Inconsistencies in the data, such as cycles, do not
+// prevent building the tree, they are reported by Warnings.
+type HouseTree struct {
+	houses map[goiaf.HouseID]goiaf.House
+
+	overlords map[goiaf.HouseID]goiaf.HouseID
+	vassals   map[goiaf.HouseID][]goiaf.HouseID
+	cadets    map[goiaf.HouseID][]goiaf.HouseID
+	parents   map[goiaf.HouseID][]goiaf.HouseID
+
+	warnings []Warning
+}
+
+// NewHouseTree builds a HouseTree from the houses. Houses are identified
+// by their ID method, houses without a valid id are left out.
+func NewHouseTree(houses []goiaf.House) *HouseTree {
+	t := &HouseTree{
+		houses:    map[goiaf.HouseID]goiaf.House{},
+		overlords: map[goiaf.HouseID]goiaf.HouseID{},
+		vassals:   map[goiaf.HouseID][]goiaf.HouseID{},
+		cadets:    map[goiaf.HouseID][]goiaf.HouseID{},
+		parents:   map[goiaf.HouseID][]goiaf.HouseID{},
+	}
+
+	for _, house := range houses {
+		if id, ok := house.ID(); ok {
+			t.houses[id] = house
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(t.houses)) {
+		house := t.houses[id]
+
+		if house.OverlordID != nil {
+			overlord := *house.OverlordID
+			t.overlords[id] = overlord
+			t.vassals[overlord] = appendUnique(t.vassals[overlord], id)
+			t.checkExists(id, overlord, "overlord")
+		}
+		for _, cadet := range house.CadetBranchesIds {
+			t.cadets[id] = appendUnique(t.cadets[id], cadet)
+			t.parents[cadet] = appendUnique(t.parents[cadet], id)
+			t.checkExists(id, cadet, "cadet branch")
+		}
+	}
+
+	t.checkFealtyCycles()
+	t.checkCadetCycles()
+	t.checkCadets()
+
+	return t
+}
+
+// FetchHouseTree fetches all houses through the client and builds a
+// HouseTree from them.
+func FetchHouseTree(ctx context.Context, client goiaf.Client) (*HouseTree, error) {
+	houses, err := goiaf.FetchAllHouses(ctx, client, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHouseTree(houses), nil
+}
+
+// House returns the house with the given id, and whether it is part of the tree.
+func (t *HouseTree) House(id goiaf.HouseID) (goiaf.House, bool) {
+	house, ok := t.houses[id]
+	return house, ok
+}
+
+// Houses returns the ids of all houses of the tree, in ascending order.
+func (t *HouseTree) Houses() []goiaf.HouseID {
+	return slices.Sorted(maps.Keys(t.houses))
+}
+
+// Warnings returns the inconsistencies found while building the tree.
+func (t *HouseTree) Warnings() []Warning {
+	return append([]Warning{}, t.warnings...)
+}
+
+// Overlord returns the id of the house the house answers to, and whether
+// it has one.
+func (t *HouseTree) Overlord(id goiaf.HouseID) (goiaf.HouseID, bool) {
+	overlord, ok := t.overlords[id]
+	return overlord, ok
+}
+
+// DirectVassals returns the ids of the houses which answer directly to the house.
+func (t *HouseTree) DirectVassals(id goiaf.HouseID) []goiaf.HouseID {
+	return append([]goiaf.HouseID{}, t.vassals[id]...)
+}
+
+// Vassals returns the ids of all houses which answer to the house, directly
+// or through other vassals, ordered by their distance to the house.
+func (t *HouseTree) Vassals(id goiaf.HouseID) []goiaf.HouseID {
+	return walkHouses(id, func(current goiaf.HouseID) []goiaf.HouseID {
+		return t.vassals[current]
+	})
+}
+
+// ChainOfFealty returns the ids of the overlord of the house, its overlord,
+// and so on, up to a house which answers to no one, such as the Iron Throne.
+// The chain stops before a house would be repeated.
+func (t *HouseTree) ChainOfFealty(id goiaf.HouseID) []goiaf.HouseID {
+	chain := []goiaf.HouseID{}
+	visited := map[goiaf.HouseID]bool{id: true}
+
+	for {
+		overlord, ok := t.overlords[id]
+		if !ok || visited[overlord] {
+			return chain
+		}
+
+		visited[overlord] = true
+		chain = append(chain, overlord)
+		id = overlord
+	}
+}
+
+// DirectCadetBranches returns the ids of the houses founded from the house.
+func (t *HouseTree) DirectCadetBranches(id goiaf.HouseID) []goiaf.HouseID {
+	return append([]goiaf.HouseID{}, t.cadets[id]...)
+}
+
+// CadetBranches returns the ids of the houses founded from the house, and
+// of the houses founded from those, ordered by their distance to the house.
+func (t *HouseTree) CadetBranches(id goiaf.HouseID) []goiaf.HouseID {
+	return walkHouses(id, func(current goiaf.HouseID) []goiaf.HouseID {
+		return t.cadets[current]
+	})
+}
+
+// RootHouses returns the ids of the houses of the tree which answer to no
+// house of the tree, in ascending order.
+func (t *HouseTree) RootHouses() []goiaf.HouseID {
+	roots := []goiaf.HouseID{}
+	for _, id := range slices.Sorted(maps.Keys(t.houses)) {
+		overlord, ok := t.overlords[id]
+		if _, exists := t.houses[overlord]; !ok || !exists {
+			roots = append(roots, id)
+		}
+	}
+
+	return roots
+}
+
+func (t *HouseTree) warn(kind WarningKind, house goiaf.HouseID, related []goiaf.HouseID, format string, args ...interface{}) {
+	t.warnings = append(t.warnings, Warning{
+		Kind:    kind,
+		House:   house,
+		Related: related,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (t *HouseTree) name(id goiaf.HouseID) string {
+	if house, ok := t.houses[id]; ok && house.Name != "" {
+		return fmt.Sprintf("%s (%d)", house.Name, id)
+	}
+
+	return fmt.Sprintf("house %d", id)
+}
+
+func (t *HouseTree) checkExists(id, related goiaf.HouseID, role string) {
+	if _, ok := t.houses[related]; !ok {
+		t.warn(WarningMissingHouse, id, []goiaf.HouseID{related},
+			"%s refers to %s as %s, which is not part of the tree", t.name(id), t.name(related), role)
+	}
+}
+
+// checkFealtyCycles reports every cycle of overlords once.
+func (t *HouseTree) checkFealtyCycles() {
+	reported := map[goiaf.HouseID]bool{}
+
+	for _, start := range slices.Sorted(maps.Keys(t.houses)) {
+		path := []goiaf.HouseID{}
+		index := map[goiaf.HouseID]int{}
+
+		for id, ok := start, true; ok; id, ok = t.overlords[id] {
+			if reported[id] {
+				break
+			}
+			if i, seen := index[id]; seen {
+				cycle := path[i:]
+				for _, member := range cycle {
+					reported[member] = true
+				}
+				t.warn(WarningFealtyCycle, cycle[0], cycle,
+					"%s is its own overlord through %d houses", t.name(cycle[0]), len(cycle))
+				break
+			}
+			index[id] = len(path)
+			path = append(path, id)
+		}
+	}
+}
+
+// checkCadetCycles reports every cycle of cadet branches once. As a house
+// can have several cadet branches, the cycles are found with a depth first
+// search, and a house which has been searched from is not searched again.
+func (t *HouseTree) checkCadetCycles() {
+	done := map[goiaf.HouseID]bool{}
+
+	var search func(id goiaf.HouseID, path []goiaf.HouseID, index map[goiaf.HouseID]int)
+	search = func(id goiaf.HouseID, path []goiaf.HouseID, index map[goiaf.HouseID]int) {
+		index[id] = len(path)
+		path = append(path, id)
+
+		for _, cadet := range t.cadets[id] {
+			if i, onPath := index[cadet]; onPath {
+				cycle := slices.Clone(path[i:])
+				t.warn(WarningCadetCycle, cycle[0], cycle,
+					"%s is a cadet branch of itself through %d houses", t.name(cycle[0]), len(cycle))
+				continue
+			}
+			if !done[cadet] {
+				search(cadet, path, index)
+			}
+		}
+
+		delete(index, id)
+		done[id] = true
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(t.cadets)) {
+		if !done[id] {
+			search(id, nil, map[goiaf.HouseID]int{})
+		}
+	}
+}
+
+// checkCadets compares cadet branches with the houses they were founded from.
+func (t *HouseTree) checkCadets() {
+	for _, cadet := range slices.Sorted(maps.Keys(t.parents)) {
+		parents := t.parents[cadet]
+		if len(parents) > 1 {
+			t.warn(WarningMultipleParentHouses, cadet, parents,
+				"%s is a cadet branch of %d houses", t.name(cadet), len(parents))
+		}
+
+		overlord, ok := t.overlords[cadet]
+		if !ok {
+			continue
+		}
+		for _, parent := range parents {
+			if overlord == parent || slices.Contains(t.ChainOfFealty(parent), overlord) {
+				continue
+			}
+			t.warn(WarningCadetOverlordMismatch, cadet, []goiaf.HouseID{parent, overlord},
+				"%s is a cadet branch of %s but answers to %s", t.name(cadet), t.name(parent), t.name(overlord))
+		}
+	}
+}
+
+// walkHouses does a breadth first search from the house, following next.
+// The house itself is only included if it can be reached from itself.
+func walkHouses(id goiaf.HouseID, next func(goiaf.HouseID) []goiaf.HouseID) []goiaf.HouseID {
+	visited := map[goiaf.HouseID]bool{}
+	result := []goiaf.HouseID{}
+
+	queue := []goiaf.HouseID{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, related := range next(current) {
+			if !visited[related] {
+				visited[related] = true
+				result = append(result, related)
+				queue = append(queue, related)
+			}
+		}
+	}
+
+	return result
+}
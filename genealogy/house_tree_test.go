@@ -0,0 +1,201 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package genealogy
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/internal/testfixture"
+)
+
+// westeros is the hierarchy used by the tests:
+//
+//	1 Iron Throne
+//	├── 2 Stark (cadet branch 3)
+//	│   ├── 3 Karstark
+//	│   └── 4 Bolton
+//	│       └── 5 Dreadfort Vassal
+//	└── 9 Lannister
+func westeros() []goiaf.House {
+	return []goiaf.House{
+		testfixture.House(1, "Iron Throne", 0),
+		testfixture.House(2, "Stark", 1, 3),
+		testfixture.House(3, "Karstark", 1),
+		testfixture.House(4, "Bolton", 2),
+		testfixture.House(5, "Dreadfort Vassal", 4),
+		testfixture.House(9, "Lannister", 1),
+		{URL: "", Name: "House without id"},
+	}
+}
+
+func warningKinds(warnings []Warning) []WarningKind {
+	kinds := []WarningKind{}
+	for _, warning := range warnings {
+		kinds = append(kinds, warning.Kind)
+	}
+
+	return kinds
+}
+
+func TestHouseTree(t *testing.T) {
+	tree := NewHouseTree(westeros())
+
+	tests := []struct {
+		name string
+		got  []goiaf.HouseID
+		want []goiaf.HouseID
+	}{
+		{"Houses", tree.Houses(), []goiaf.HouseID{1, 2, 3, 4, 5, 9}},
+		{"RootHouses", tree.RootHouses(), []goiaf.HouseID{1}},
+		{"DirectVassals(1)", tree.DirectVassals(1), []goiaf.HouseID{2, 3, 9}},
+		{"Vassals(1)", tree.Vassals(1), []goiaf.HouseID{2, 3, 9, 4, 5}},
+		{"Vassals(5)", tree.Vassals(5), []goiaf.HouseID{}},
+		{"ChainOfFealty(5)", tree.ChainOfFealty(5), []goiaf.HouseID{4, 2, 1}},
+		{"ChainOfFealty(1)", tree.ChainOfFealty(1), []goiaf.HouseID{}},
+		{"CadetBranches(2)", tree.CadetBranches(2), []goiaf.HouseID{3}},
+	}
+	for _, test := range tests {
+		if !slices.Equal(test.got, test.want) {
+			t.Errorf("%s = %v, want %v", test.name, test.got, test.want)
+		}
+	}
+
+	// Karstark answers to the Iron Throne, which is in the chain of
+	// fealty of Stark, so it is not reported.
+	if warnings := tree.Warnings(); len(warnings) != 0 {
+		t.Errorf("Warnings() = %v, want none", warnings)
+	}
+}
+
+func TestHouseTreeMissingHouse(t *testing.T) {
+	tree := NewHouseTree(append(westeros(), testfixture.House(6, "Frey", 7, 8)))
+
+	warnings := tree.Warnings()
+	if kinds := warningKinds(warnings); !slices.Equal(kinds, []WarningKind{WarningMissingHouse, WarningMissingHouse}) {
+		t.Fatalf("Warnings() = %v, want two missing houses", warnings)
+	}
+	if warnings[0].House != 6 || !slices.Equal(warnings[0].Related, []goiaf.HouseID{7}) {
+		t.Errorf("Warnings()[0] = %+v, want house 6 referring to 7", warnings[0])
+	}
+	if want := "Frey (6) refers to house 7 as overlord, which is not part of the tree"; warnings[0].String() != want {
+		t.Errorf("Warnings()[0].String() = %q, want %q", warnings[0], want)
+	}
+
+	// A house answering to a missing house is a root of the tree.
+	if roots := tree.RootHouses(); !slices.Equal(roots, []goiaf.HouseID{1, 6}) {
+		t.Errorf("RootHouses() = %v, want [1 6]", roots)
+	}
+}
+
+func TestHouseTreeFealtyCycle(t *testing.T) {
+	tree := NewHouseTree([]goiaf.House{
+		testfixture.House(10, "Blackwood", 11),
+		testfixture.House(11, "Bracken", 10),
+		testfixture.House(12, "Vassal", 10),
+	})
+
+	warnings := tree.Warnings()
+	if len(warnings) != 1 || warnings[0].Kind != WarningFealtyCycle {
+		t.Fatalf("Warnings() = %v, want one fealty cycle", warnings)
+	}
+	if warnings[0].House != 10 || !slices.Equal(warnings[0].Related, []goiaf.HouseID{10, 11}) {
+		t.Errorf("Warnings()[0] = %+v, want the cycle of houses 10 and 11", warnings[0])
+	}
+
+	if chain := tree.ChainOfFealty(12); !slices.Equal(chain, []goiaf.HouseID{10, 11}) {
+		t.Errorf("ChainOfFealty(12) = %v, want [10 11]", chain)
+	}
+	if roots := tree.RootHouses(); len(roots) != 0 {
+		t.Errorf("RootHouses() = %v, want none", roots)
+	}
+}
+
+func TestHouseTreeCadetCycle(t *testing.T) {
+	tree := NewHouseTree([]goiaf.House{
+		testfixture.House(12, "Elder", 0, 13),
+		testfixture.House(13, "Younger", 0, 12),
+	})
+
+	warnings := tree.Warnings()
+	if kinds := warningKinds(warnings); !slices.Equal(kinds, []WarningKind{WarningCadetCycle}) {
+		t.Fatalf("Warnings() = %v, want one cadet cycle", warnings)
+	}
+	if warnings[0].House != 12 || !slices.Equal(warnings[0].Related, []goiaf.HouseID{12, 13}) {
+		t.Errorf("Warnings()[0] = %+v, want the cycle of houses 12 and 13", warnings[0])
+	}
+	if want := "Elder (12) is a cadet branch of itself through 2 houses"; warnings[0].String() != want {
+		t.Errorf("Warnings()[0] = %q, want %q", warnings[0].String(), want)
+	}
+	if cadets := tree.CadetBranches(12); !slices.Equal(cadets, []goiaf.HouseID{13, 12}) {
+		t.Errorf("CadetBranches(12) = %v, want [13 12]", cadets)
+	}
+}
+
+func TestHouseTreeCadetCycles(t *testing.T) {
+	// 20 and 21 form a cycle, and so do 21, 22 and 23, which share 21 with
+	// the first. 24 is its own cadet branch, 25 only leads into a cycle.
+	// 21 and 22 are also cadet branches of two houses, which is reported
+	// separately.
+	tree := NewHouseTree([]goiaf.House{
+		testfixture.House(20, "A", 0, 21),
+		testfixture.House(21, "B", 0, 20, 22),
+		testfixture.House(22, "C", 0, 23),
+		testfixture.House(23, "D", 0, 21),
+		testfixture.House(24, "E", 0, 24),
+		testfixture.House(25, "F", 0, 22),
+	})
+
+	cycles := [][]goiaf.HouseID{}
+	for _, warning := range tree.Warnings() {
+		if warning.Kind == WarningCadetCycle {
+			cycles = append(cycles, warning.Related)
+		}
+	}
+
+	want := [][]goiaf.HouseID{{20, 21}, {21, 22, 23}, {24}}
+	if fmt.Sprint(cycles) != fmt.Sprint(want) {
+		t.Errorf("cadet cycles = %v, want %v", cycles, want)
+	}
+}
+
+func TestHouseTreeCadetOverlordMismatch(t *testing.T) {
+	houses := westeros()
+	houses[1] = testfixture.House(2, "Stark", 1, 3, 8)
+	houses[5] = testfixture.House(9, "Lannister", 1, 8)
+	houses = append(houses, testfixture.House(8, "Cadet", 2))
+
+	tree := NewHouseTree(houses)
+
+	warnings := tree.Warnings()
+	want := []WarningKind{WarningMultipleParentHouses, WarningCadetOverlordMismatch}
+	if kinds := warningKinds(warnings); !slices.Equal(kinds, want) {
+		t.Fatalf("Warnings() = %v, want %v", warnings, want)
+	}
+
+	// Cadet answers to Stark, which it was founded from, but neither to
+	// Lannister nor to one of its overlords.
+	if !slices.Equal(warnings[0].Related, []goiaf.HouseID{2, 9}) {
+		t.Errorf("Warnings()[0].Related = %v, want [2 9]", warnings[0].Related)
+	}
+	mismatch := warnings[1]
+	if mismatch.House != 8 || !slices.Equal(mismatch.Related, []goiaf.HouseID{9, 2}) {
+		t.Errorf("Warnings()[1] = %+v, want house 8 founded from 9 answering to 2", mismatch)
+	}
+	if want := "Cadet (8) is a cadet branch of Lannister (9) but answers to Stark (2)"; mismatch.String() != want {
+		t.Errorf("Warnings()[1].String() = %q, want %q", mismatch, want)
+	}
+}
+
+func TestWarningKindString(t *testing.T) {
+	if s := WarningCadetOverlordMismatch.String(); s != "cadet overlord mismatch" {
+		t.Errorf("String() = %q, want cadet overlord mismatch", s)
+	}
+	if s := WarningKind(100).String(); s != "unknown" {
+		t.Errorf("String() = %q, want unknown", s)
+	}
+}
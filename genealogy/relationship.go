@@ -0,0 +1,189 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package genealogy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+// Relationship describes how one character is related to another.
+type Relationship struct {
+	// The character the relationship is described from.
+	From goiaf.CharacterID
+
+	// The related character.
+	To goiaf.CharacterID
+
+	// The name of the relationship of To to From, e.g. "father",
+	// "half-sister" or "second cousin once removed".
+	Name string
+
+	// The closest common ancestor, if the characters are related by blood.
+	Ancestor *goiaf.CharacterID
+
+	// The number of generations from From up to the common ancestor.
+	Up int
+
+	// The number of generations from the common ancestor down to To.
+	Down int
+}
+
+// String makes the Relationship type implement the fmt.Stringer interface.
+func (r Relationship) String() string {
+	return r.Name
+}
+
+// Relationship returns the closest relationship of the character to to the
+// character from, and whether the characters are related. Relationships by
+// blood are found through the closest common ancestor. Characters which are
+// not related by blood but married to each other are spouses.
+func (g *FamilyGraph) Relationship(from, to goiaf.CharacterID) (Relationship, bool) {
+	r := Relationship{From: from, To: to}
+	if from == to {
+		r.Name = "self"
+		return r, true
+	}
+
+	fromAncestors := g.generations(from)
+	toAncestors := g.generations(to)
+
+	best, found := goiaf.CharacterID(0), false
+	for ancestor, up := range fromAncestors {
+		down, ok := toAncestors[ancestor]
+		if !ok {
+			continue
+		}
+		if !found || up+down < r.Up+r.Down || (up+down == r.Up+r.Down && ancestor < best) {
+			best, found = ancestor, true
+			r.Up, r.Down = up, down
+		}
+	}
+
+	if !found {
+		for _, spouse := range g.spouses[from] {
+			if spouse == to {
+				r.Name = g.gendered(to, "spouse", "husband", "wife")
+				return r, true
+			}
+		}
+		return r, false
+	}
+
+	r.Ancestor = &best
+	r.Name = g.name(r)
+	return r, true
+}
+
+// generations returns the ancestors of the character, including itself,
+// with the number of generations to reach them.
+func (g *FamilyGraph) generations(id goiaf.CharacterID) map[goiaf.CharacterID]int {
+	result := map[goiaf.CharacterID]int{id: 0}
+
+	generation := []goiaf.CharacterID{id}
+	for level := 1; len(generation) > 0; level++ {
+		next := []goiaf.CharacterID{}
+		for _, current := range generation {
+			for _, parent := range g.Parents(current) {
+				if _, ok := result[parent]; !ok {
+					result[parent] = level
+					next = append(next, parent)
+				}
+			}
+		}
+		generation = next
+	}
+
+	return result
+}
+
+func (g *FamilyGraph) name(r Relationship) string {
+	up, down := r.Up, r.Down
+
+	switch {
+	case up == 0:
+		return greats(down-2, "grand") + g.gendered(r.To, "child", "son", "daughter")
+	case down == 0:
+		return greats(up-2, "grand") + g.gendered(r.To, "parent", "father", "mother")
+	case up == 1 && down == 1:
+		name := g.gendered(r.To, "sibling", "brother", "sister")
+		if g.sharedParents(r.From, r.To) < 2 {
+			name = "half-" + name
+		}
+		return name
+	case up == 1:
+		prefix := greats(down-3, "grand")
+		return g.gendered(r.To, prefix+"niece or "+prefix+"nephew", prefix+"nephew", prefix+"niece")
+	case down == 1:
+		prefix := greats(up-3, "great-")
+		return g.gendered(r.To, prefix+"aunt or "+prefix+"uncle", prefix+"uncle", prefix+"aunt")
+	}
+
+	degree := min(up, down) - 1
+	removed := up - down
+	if removed < 0 {
+		removed = -removed
+	}
+
+	name := ordinal(degree) + " cousin"
+	switch removed {
+	case 0:
+	case 1:
+		name += " once removed"
+	case 2:
+		name += " twice removed"
+	default:
+		name += fmt.Sprintf(" %d times removed", removed)
+	}
+
+	return name
+}
+
+// gendered returns the term matching the gender of the character.
+func (g *FamilyGraph) gendered(id goiaf.CharacterID, neutral, male, female string) string {
+	switch g.characters[id].Gender {
+	case "Male":
+		return male
+	case "Female":
+		return female
+	}
+
+	return neutral
+}
+
+// greats returns the prefix for n generations beyond the closest one, e.g.
+// "grand" for one and "great-grand" for two.
+func greats(n int, first string) string {
+	if n < 0 {
+		return ""
+	}
+	if n == 0 {
+		return first
+	}
+
+	return strings.Repeat("great-", n) + first
+}
+
+func ordinal(n int) string {
+	names := []string{"", "first", "second", "third", "fourth", "fifth", "sixth", "seventh", "eighth", "ninth", "tenth"}
+	if n > 0 && n < len(names) {
+		return names[n]
+	}
+
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package genealogy
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/internal/testfixture"
+)
+
+// starks is the family used by the tests, see testfixture.Starks.
+func starks() *FamilyGraph {
+	return NewFamilyGraph(testfixture.Starks())
+}
+
+func TestRelationship(t *testing.T) {
+	g := starks()
+
+	tests := []struct {
+		from, to goiaf.CharacterID
+		name     string
+		up, down int
+	}{
+		{6, 6, "self", 0, 0},
+		{6, 3, "father", 1, 0},
+		{6, 2, "grandmother", 2, 0},
+		{11, 1, "great-grandfather", 3, 0},
+		{1, 11, "great-grandchild", 0, 3},
+		{6, 7, "sister", 1, 1},
+		{8, 7, "half-sister", 1, 1},
+		{7, 8, "half-brother", 1, 1},
+		{6, 5, "uncle", 2, 1},
+		{11, 5, "great-uncle", 3, 1},
+		{5, 6, "nephew", 1, 2},
+		// Rickon has no gender, so the neutral term is used.
+		{5, 11, "grandniece or grandnephew", 1, 3},
+		{6, 12, "first cousin", 2, 2},
+		{11, 12, "first cousin once removed", 3, 2},
+		{11, 14, "second cousin once removed", 3, 4},
+		{14, 11, "second cousin once removed", 4, 3},
+		{6, 14, "first cousin twice removed", 2, 4},
+	}
+
+	for _, test := range tests {
+		r, ok := g.Relationship(test.from, test.to)
+		if !ok {
+			t.Errorf("Relationship(%d, %d) found no relationship, want %q", test.from, test.to, test.name)
+			continue
+		}
+		if r.Name != test.name || r.Up != test.up || r.Down != test.down {
+			t.Errorf("Relationship(%d, %d) = %q up %d down %d, want %q up %d down %d",
+				test.from, test.to, r.Name, r.Up, r.Down, test.name, test.up, test.down)
+		}
+	}
+}
+
+func TestRelationshipAncestor(t *testing.T) {
+	g := starks()
+
+	// Rickard and Lyarra are both closest common ancestors, the lower id wins.
+	r, _ := g.Relationship(11, 14)
+	if r.Ancestor == nil || *r.Ancestor != 1 {
+		t.Errorf("Ancestor = %v, want 1", r.Ancestor)
+	}
+	if r.String() != "second cousin once removed" {
+		t.Errorf("String() = %q, want the name", r)
+	}
+}
+
+func TestRelationshipSpouse(t *testing.T) {
+	g := starks()
+
+	if r, ok := g.Relationship(3, 4); !ok || r.Name != "wife" || r.Ancestor != nil {
+		t.Errorf("Relationship(3, 4) = %+v, %v, want wife", r, ok)
+	}
+	if r, ok := g.Relationship(4, 3); !ok || r.Name != "husband" {
+		t.Errorf("Relationship(4, 3) = %+v, %v, want husband", r, ok)
+	}
+	if r, ok := g.Relationship(6, 20); ok {
+		t.Errorf("Relationship(6, 20) = %q, want no relationship", r)
+	}
+}
+
+func TestFamilyGraphRelatives(t *testing.T) {
+	g := starks()
+
+	tests := []struct {
+		name string
+		got  []goiaf.CharacterID
+		want []goiaf.CharacterID
+	}{
+		{"Siblings(6)", g.Siblings(6), []goiaf.CharacterID{7}},
+		{"HalfSiblings(6)", g.HalfSiblings(6), []goiaf.CharacterID{8}},
+		{"HalfSiblings(8)", g.HalfSiblings(8), []goiaf.CharacterID{6, 7}},
+		{"Cousins(6)", g.Cousins(6), []goiaf.CharacterID{12}},
+		{"Children(3)", g.Children(3), []goiaf.CharacterID{6, 7, 8}},
+		{"Spouses(4)", g.Spouses(4), []goiaf.CharacterID{3}},
+		{"Ancestors(11, 2)", g.Ancestors(11, 2), []goiaf.CharacterID{6, 3, 4}},
+		{"Ancestors(11, 0)", g.Ancestors(11, 0), []goiaf.CharacterID{6, 3, 4, 1, 2}},
+		{"Descendants(5, 0)", g.Descendants(5, 0), []goiaf.CharacterID{12, 13, 14}},
+	}
+	for _, test := range tests {
+		if !slices.Equal(test.got, test.want) {
+			t.Errorf("%s = %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	tests := map[int]string{1: "first", 2: "second", 10: "tenth", 11: "11th", 12: "12th", 21: "21st", 22: "22nd", 23: "23rd", 111: "111th"}
+	for n, want := range tests {
+		if got := ordinal(n); got != want {
+			t.Errorf("ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaftest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+// linkHeader returns an RFC 5988 link header for the pagination methods of a
+// response, in the order first, prev, next and last.
+func linkHeader[R goiaf.ParamConverter](endpoint string, first, prev, next, last func() (R, error)) string {
+	links := []string{}
+
+	for _, l := range []struct {
+		rel     string
+		request func() (R, error)
+	}{
+		{"first", first},
+		{"prev", prev},
+		{"next", next},
+		{"last", last},
+	} {
+		request, err := l.request()
+		if err != nil {
+			continue
+		}
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, endpoint, request.Convert().Encode(), l.rel))
+	}
+
+	return strings.Join(links, ", ")
+}
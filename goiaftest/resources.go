@@ -0,0 +1,310 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaftest
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+const (
+	releasedLayout = "2006-01-02T15:04:05"
+)
+
+// The types below have the same JSON format as the resources of the api.
+
+type book struct {
+	URL           string   `json:"url"`
+	Name          string   `json:"name"`
+	ISBN          string   `json:"isbn"`
+	Authors       []string `json:"authors"`
+	NumberOfPages int      `json:"numberOfPages"`
+	Publisher     string   `json:"publisher"`
+	Country       string   `json:"country"`
+	MediaType     string   `json:"mediaType"`
+	Released      string   `json:"released"`
+	Characters    []string `json:"characters"`
+	PovCharacters []string `json:"povCharacters"`
+}
+
+type character struct {
+	URL         string   `json:"url"`
+	Name        string   `json:"name"`
+	Gender      string   `json:"gender"`
+	Culture     string   `json:"culture"`
+	Born        string   `json:"born"`
+	Died        string   `json:"died"`
+	Titles      []string `json:"titles"`
+	Aliases     []string `json:"aliases"`
+	Father      string   `json:"father"`
+	Mother      string   `json:"mother"`
+	Spouse      string   `json:"spouse"`
+	Allegiances []string `json:"allegiances"`
+	Books       []string `json:"books"`
+	PovBooks    []string `json:"povBooks"`
+	TvSeries    []string `json:"tvSeries"`
+	PlayedBy    []string `json:"playedBy"`
+}
+
+type house struct {
+	URL              string   `json:"url"`
+	Name             string   `json:"name"`
+	Region           string   `json:"region"`
+	CoatOfArms       string   `json:"coatOfArms"`
+	Words            string   `json:"words"`
+	Titles           []string `json:"titles"`
+	Seats            []string `json:"seats"`
+	CurrentLord      string   `json:"currentLord"`
+	Heir             string   `json:"heir"`
+	Overlord         string   `json:"overlord"`
+	Founded          string   `json:"founded"`
+	Founder          string   `json:"founder"`
+	DiedOut          string   `json:"diedOut"`
+	AncestralWeapons []string `json:"ancestralWeapons"`
+	CadetBranches    []string `json:"cadetBranches"`
+	SwornMembers     []string `json:"swornMembers"`
+}
+
+func newBook(base string, b goiaf.Book) book {
+	// The offline client only serves books with a valid id.
+	id, _ := b.ID()
+
+	return book{
+		URL:           resourceURL(base, "books", id),
+		Name:          b.Name,
+		ISBN:          b.ISBN,
+		Authors:       apiStrings(b.Authors),
+		NumberOfPages: b.NumberOfPages,
+		Publisher:     b.Publisher,
+		Country:       b.Country,
+		MediaType:     b.MediaType,
+		Released:      b.Released.Format(releasedLayout),
+		Characters:    resourceURLs(base, "characters", b.CharacterIds),
+		PovCharacters: resourceURLs(base, "characters", b.PovCharacterIds),
+	}
+}
+
+func newCharacter(base string, c goiaf.Character) character {
+	// The offline client only serves characters with a valid id.
+	id, _ := c.ID()
+
+	return character{
+		URL:         resourceURL(base, "characters", id),
+		Name:        c.Name,
+		Gender:      c.Gender,
+		Culture:     c.Culture,
+		Born:        c.Born,
+		Died:        c.Died,
+		Titles:      apiStrings(c.Titles),
+		Aliases:     apiStrings(c.Aliases),
+		Father:      optionalURL(base, "characters", c.FatherID),
+		Mother:      optionalURL(base, "characters", c.MotherID),
+		Spouse:      optionalURL(base, "characters", c.SpouseID),
+		Allegiances: resourceURLs(base, "houses", c.AllegianceIds),
+		Books:       resourceURLs(base, "books", c.BookIds),
+		PovBooks:    resourceURLs(base, "books", c.PovBookIds),
+		TvSeries:    apiStrings(c.TvSeries),
+		PlayedBy:    apiStrings(c.PlayedBy),
+	}
+}
+
+func newHouse(base string, h goiaf.House) house {
+	// The offline client only serves houses with a valid id.
+	id, _ := h.ID()
+
+	return house{
+		URL:              resourceURL(base, "houses", id),
+		Name:             h.Name,
+		Region:           h.Region,
+		CoatOfArms:       h.CoatOfArms,
+		Words:            h.Words,
+		Titles:           apiStrings(h.Titles),
+		Seats:            apiStrings(h.Seats),
+		CurrentLord:      optionalURL(base, "characters", h.CurrentLordID),
+		Heir:             optionalURL(base, "characters", h.HeirID),
+		Overlord:         optionalURL(base, "houses", h.OverlordID),
+		Founded:          h.Founded,
+		Founder:          optionalURL(base, "characters", h.FounderID),
+		DiedOut:          h.DiedOut,
+		AncestralWeapons: apiStrings(h.AncestralWeapons),
+		CadetBranches:    resourceURLs(base, "houses", h.CadetBranchesIds),
+		SwornMembers:     resourceURLs(base, "characters", h.SwornMembersIds),
+	}
+}
+
+// apiStrings returns the values, or a list with an empty string if there are
+// none, which is how the api encodes empty lists of names.
+func apiStrings(values []string) []string {
+	if len(values) == 0 {
+		return []string{""}
+	}
+
+	return values
+}
+
+func resourceURL[T ~int](base, name string, id T) string {
+	return fmt.Sprintf("%s/%s/%d", base, name, id)
+}
+
+// optionalURL returns the URL of a resource, or an empty string if there
+// is no resource.
+func optionalURL[T ~int](base, name string, id *T) string {
+	if id == nil {
+		return ""
+	}
+
+	return resourceURL(base, name, *id)
+}
+
+func resourceURLs[T ~int](base, name string, ids []T) []string {
+	urls := []string{}
+	for _, id := range ids {
+		urls = append(urls, resourceURL(base, name, id))
+	}
+
+	return urls
+}
+
+// The functions below build requests from the query parameters of the api.
+
+func pagination(query map[string][]string, page func(int), pageSize func(int)) error {
+	if values := query["page"]; len(values) > 0 {
+		value, err := strconv.Atoi(values[0])
+		if err != nil {
+			return err
+		}
+		page(value)
+	}
+	if values := query["pageSize"]; len(values) > 0 {
+		value, err := strconv.Atoi(values[0])
+		if err != nil {
+			return err
+		}
+		pageSize(value)
+	}
+
+	return nil
+}
+
+func bookRequest(query map[string][]string) (goiaf.BookRequest, error) {
+	request := goiaf.NewBookRequest()
+
+	err := pagination(query,
+		func(v int) { request = request.Page(v) },
+		func(v int) { request = request.PageSize(v) })
+	if err != nil {
+		return nil, err
+	}
+
+	if value := get(query, "name"); value != "" {
+		request = request.Name(value)
+	}
+	if value := get(query, "fromReleaseDate"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, err
+		}
+		request = request.FromReleaseDate(t)
+	}
+	if value := get(query, "toReleaseDate"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, err
+		}
+		request = request.ToReleaseDate(t)
+	}
+
+	return request, nil
+}
+
+func characterRequest(query map[string][]string) (goiaf.CharacterRequest, error) {
+	request := goiaf.NewCharacterRequest()
+
+	err := pagination(query,
+		func(v int) { request = request.Page(v) },
+		func(v int) { request = request.PageSize(v) })
+	if err != nil {
+		return nil, err
+	}
+
+	if value := get(query, "name"); value != "" {
+		request = request.Name(value)
+	}
+	if value := get(query, "gender"); value != "" {
+		request = request.Gender(value)
+	}
+	if value := get(query, "culture"); value != "" {
+		request = request.Culture(value)
+	}
+	if value := get(query, "born"); value != "" {
+		request = request.Born(value)
+	}
+	if value := get(query, "died"); value != "" {
+		request = request.Died(value)
+	}
+	if value := get(query, "isAlive"); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		request = request.IsAlive(b)
+	}
+
+	return request, nil
+}
+
+func houseRequest(query map[string][]string) (goiaf.HouseRequest, error) {
+	request := goiaf.NewHouseRequest()
+
+	err := pagination(query,
+		func(v int) { request = request.Page(v) },
+		func(v int) { request = request.PageSize(v) })
+	if err != nil {
+		return nil, err
+	}
+
+	if value := get(query, "name"); value != "" {
+		request = request.Name(value)
+	}
+	if value := get(query, "region"); value != "" {
+		request = request.Region(value)
+	}
+	if value := get(query, "words"); value != "" {
+		request = request.Words(value)
+	}
+
+	flags := []struct {
+		name string
+		set  func(bool) goiaf.HouseRequest
+	}{
+		{"hasWords", func(v bool) goiaf.HouseRequest { return request.HasWords(v) }},
+		{"hasTitles", func(v bool) goiaf.HouseRequest { return request.HasTitles(v) }},
+		{"hasSeats", func(v bool) goiaf.HouseRequest { return request.HasSeats(v) }},
+		{"hasDiedOut", func(v bool) goiaf.HouseRequest { return request.HasDiedOut(v) }},
+		{"hasAncestralWeapons", func(v bool) goiaf.HouseRequest { return request.HasAncestralWeapons(v) }},
+	}
+	for _, flag := range flags {
+		if value := get(query, flag.name); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, err
+			}
+			request = flag.set(b)
+		}
+	}
+
+	return request, nil
+}
+
+func get(query map[string][]string, name string) string {
+	if values := query[name]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
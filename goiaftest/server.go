@@ -0,0 +1,354 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package goiaftest provides an in-process fake of An API Of Ice And Fire for tests.
+
+The server serves the books, characters and houses it is seeded with in the same
+JSON format as the api, including the link header used for pagination, and can
+inject faults such as latency, error statuses and truncated bodies.
+
+	srv := goiaftest.NewServer(goiaftest.Fixtures{
+		Characters: []goiaf.Character{
+			{URL: "https://anapioficeandfire.com/api/characters/583", Name: "Jon Snow"},
+		},
+	})
+	defer srv.Close()
+
+	client := srv.Client()
+	character, err := client.Character(583)
+*/
+package goiaftest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+// Fixtures are the resources served by a Server. The id of every resource is
+// taken from the end of its URL, the rest of the URL is replaced by the URL
+// of the server.
+type Fixtures struct {
+	Books      []goiaf.Book      `json:"books"`
+	Characters []goiaf.Character `json:"characters"`
+	Houses     []goiaf.House     `json:"houses"`
+}
+
+// LoadFixtures reads fixtures from a JSON file with the keys books,
+// characters and houses.
+func LoadFixtures(path string) (Fixtures, error) {
+	fixtures := Fixtures{}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fixtures, err
+	}
+
+	if err := json.Unmarshal(b, &fixtures); err != nil {
+		return fixtures, err
+	}
+
+	return fixtures, fixtures.validate()
+}
+
+// validate checks that every fixture has a URL ending with a valid id.
+func (f Fixtures) validate() error {
+	for i, book := range f.Books {
+		if _, ok := book.ID(); !ok {
+			return fmt.Errorf("Book fixture %d has no id in its URL %q", i, book.URL)
+		}
+	}
+	for i, character := range f.Characters {
+		if _, ok := character.ID(); !ok {
+			return fmt.Errorf("Character fixture %d has no id in its URL %q", i, character.URL)
+		}
+	}
+	for i, house := range f.Houses {
+		if _, ok := house.ID(); !ok {
+			return fmt.Errorf("House fixture %d has no id in its URL %q", i, house.URL)
+		}
+	}
+
+	return nil
+}
+
+// Fault describes a failure injected by the server.
+type Fault struct {
+	// PathPrefix limits the fault to requests whose path starts with it,
+	// e.g. /api/characters. An empty prefix matches every request.
+	PathPrefix string
+
+	// Times is the number of requests the fault applies to. Zero means
+	// that it applies to every matching request until it is cleared.
+	Times int
+
+	// Latency delays the response.
+	Latency time.Duration
+
+	// StatusCode, if set, is returned instead of the resource, e.g.
+	// http.StatusNotFound, http.StatusTooManyRequests or
+	// http.StatusInternalServerError.
+	StatusCode int
+
+	// RetryAfter, if set, is sent as the Retry-After header, rounded up to
+	// whole seconds.
+	RetryAfter time.Duration
+
+	// Truncate cuts the response body in half while still announcing the
+	// full length, so the client fails while reading it.
+	Truncate bool
+}
+
+// Server is a fake of the api running on a local httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	client goiaf.Client
+
+	mu       sync.Mutex
+	faults   []*Fault
+	requests int
+}
+
+// NewServer starts a Server serving the fixtures. The server must be closed
+// when it is no longer used. It panics if a fixture has no id in its URL.
+func NewServer(fixtures Fixtures) *Server {
+	if err := fixtures.validate(); err != nil {
+		panic("goiaftest: " + err.Error())
+	}
+
+	s := &Server{
+		client: goiaf.NewOfflineClient(fixtures.Books, fixtures.Characters, fixtures.Houses),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// BaseURL returns the base URL of the fake api, to be used with goiaf.WithBaseURL.
+func (s *Server) BaseURL() string {
+	return s.URL + "/api"
+}
+
+// Client returns a goiaf.Client which sends its requests to the server.
+// The options are applied after the base URL has been set.
+func (s *Server) Client(opts ...goiaf.Option) goiaf.Client {
+	return goiaf.NewClient(append([]goiaf.Option{goiaf.WithBaseURL(s.BaseURL())}, opts...)...)
+}
+
+// AddFault injects a fault into the responses of the server. Faults are
+// matched in the order they were added.
+func (s *Server) AddFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Requests returns the number of requests the server has received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// fault returns the first fault matching the path, if any, and uses it up.
+func (s *Server) fault(path string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	for i, fault := range s.faults {
+		if !strings.HasPrefix(path, fault.PathPrefix) {
+			continue
+		}
+
+		f := *fault
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return &f
+	}
+
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	fault := s.fault(r.URL.Path)
+	if fault != nil && fault.Latency > 0 {
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if fault != nil && fault.StatusCode != 0 {
+		if fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(fault.RetryAfter.Seconds()))))
+		}
+		http.Error(w, http.StatusText(fault.StatusCode), fault.StatusCode)
+		return
+	}
+
+	status, link, body := s.route(r)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if link != "" {
+		w.Header().Set("Link", link)
+	}
+
+	if fault != nil && fault.Truncate {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+		w.Write(body[:len(body)/2])
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// route returns the status, link header and body of the response to the request.
+func (s *Server) route(r *http.Request) (int, string, []byte) {
+	if r.Method != http.MethodGet {
+		return errorResponse(http.StatusMethodNotAllowed)
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api"), "/")
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 {
+		return errorResponse(http.StatusNotFound)
+	}
+
+	base := s.BaseURL()
+	if len(parts) == 2 {
+		id, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return errorResponse(http.StatusNotFound)
+		}
+		return s.resource(r, base, parts[0], id)
+	}
+
+	return s.list(r, base, parts[0])
+}
+
+func (s *Server) resource(r *http.Request, base, name string, id int) (int, string, []byte) {
+	var (
+		v   interface{}
+		err error
+	)
+
+	switch name {
+	case "books":
+		var book goiaf.Book
+		book, err = s.client.BookContext(r.Context(), id)
+		v = newBook(base, book)
+	case "characters":
+		var character goiaf.Character
+		character, err = s.client.CharacterContext(r.Context(), id)
+		v = newCharacter(base, character)
+	case "houses":
+		var house goiaf.House
+		house, err = s.client.HouseContext(r.Context(), id)
+		v = newHouse(base, house)
+	default:
+		return errorResponse(http.StatusNotFound)
+	}
+	if err != nil {
+		return errorResponse(http.StatusNotFound)
+	}
+
+	return jsonResponse("", v)
+}
+
+func (s *Server) list(r *http.Request, base, name string) (int, string, []byte) {
+	query := r.URL.Query()
+
+	switch name {
+	case "books":
+		request, err := bookRequest(query)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest)
+		}
+		response, err := s.client.BooksContext(r.Context(), request)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest)
+		}
+
+		books := []book{}
+		for _, b := range response.Data {
+			books = append(books, newBook(base, b))
+		}
+		return jsonResponse(linkHeader(base+"/books", response.First, response.Prev, response.Next, response.Last), books)
+	case "characters":
+		request, err := characterRequest(query)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest)
+		}
+		response, err := s.client.CharactersContext(r.Context(), request)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest)
+		}
+
+		characters := []character{}
+		for _, c := range response.Data {
+			characters = append(characters, newCharacter(base, c))
+		}
+		return jsonResponse(linkHeader(base+"/characters", response.First, response.Prev, response.Next, response.Last), characters)
+	case "houses":
+		request, err := houseRequest(query)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest)
+		}
+		response, err := s.client.HousesContext(r.Context(), request)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest)
+		}
+
+		houses := []house{}
+		for _, h := range response.Data {
+			houses = append(houses, newHouse(base, h))
+		}
+		return jsonResponse(linkHeader(base+"/houses", response.First, response.Prev, response.Next, response.Last), houses)
+	}
+
+	return errorResponse(http.StatusNotFound)
+}
+
+func jsonResponse(link string, v interface{}) (int, string, []byte) {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return errorResponse(http.StatusInternalServerError)
+	}
+
+	return http.StatusOK, link, buf.Bytes()
+}
+
+func errorResponse(status int) (int, string, []byte) {
+	return status, "", []byte(fmt.Sprintf(`{"message":%q}`, http.StatusText(status)))
+}
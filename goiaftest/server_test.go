@@ -0,0 +1,65 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaftest
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+func TestNewServerRejectsFixturesWithoutID(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewServer accepted a fixture without id")
+		}
+	}()
+
+	NewServer(Fixtures{Characters: []goiaf.Character{{Name: "Nobody"}}})
+}
+
+func TestLoadFixturesRejectsFixturesWithoutID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	err := os.WriteFile(path, []byte(`{"houses":[{"url":"http://www.anapioficeandfire.com/api/houses/","name":"House"}]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFixtures(path); err == nil {
+		t.Error("LoadFixtures accepted a fixture without id")
+	}
+}
+
+func TestFaultRetryAfter(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		header     string
+	}{
+		{0, ""},
+		{time.Millisecond, "1"},
+		{1500 * time.Millisecond, "2"},
+		{2 * time.Second, "2"},
+	}
+
+	for _, test := range tests {
+		server := NewServer(Fixtures{})
+		server.AddFault(Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: test.retryAfter})
+
+		resp, err := http.Get(server.BaseURL() + "/books")
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if header := resp.Header.Get("Retry-After"); header != test.header {
+			t.Errorf("RetryAfter %v: Retry-After = %q, want %q", test.retryAfter, header, test.header)
+		}
+	}
+}
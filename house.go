@@ -9,52 +9,52 @@ import "encoding/json"
 // House represent the house resource in the api
 type House struct {
 	// The hypermedia URL of this resource.
-	URL string
+	URL string `json:"url"`
 
 	// The name of this house.
-	Name string
+	Name string `json:"name"`
 
 	// The region that this house resides in.
-	Region string
+	Region string `json:"region"`
 
 	// Text describing the coat of arms of this house.
-	CoatOfArms string
+	CoatOfArms string `json:"coatOfArms"`
 
 	// The words of this house.
-	Words string
+	Words string `json:"words"`
 
 	// The titles that this house holds.
-	Titles []string
+	Titles []string `json:"titles"`
 
 	// The seats that this house holds.
-	Seats []string
+	Seats []string `json:"seats"`
 
-	// The Character id of this house's current lord.
-	CurrentLordID int
+	// The Character id of this house's current lord, or nil if unknown.
+	CurrentLordID *CharacterID `json:"currentLordId"`
 
-	// The Character id of this house's heir.
-	HeirID int
+	// The Character id of this house's heir, or nil if unknown.
+	HeirID *CharacterID `json:"heirId"`
 
-	// The Houses id that this house answers to.
-	OverlordID int
+	// The Houses id that this house answers to, or nil if unknown.
+	OverlordID *HouseID `json:"overlordId"`
 
 	// The year that this house was founded.
-	Founded string
+	Founded string `json:"founded"`
 
-	// The Character resource URL that founded this house.
-	FounderID int
+	// The Character id that founded this house, or nil if unknown.
+	FounderID *CharacterID `json:"founderId"`
 
 	// The year that this house died out.
-	DiedOut string
+	DiedOut string `json:"diedOut"`
 
 	// An array of names of the noteworthy weapons that this house owns.
-	AncestralWeapons []string
+	AncestralWeapons []string `json:"ancestralWeapons"`
 
 	// An array of Houses ids that was founded from this house.
-	CadetBranchesIds []int
+	CadetBranchesIds []HouseID `json:"cadetBranchesIds"`
 
 	// An array of Character ids that are sworn to this house.
-	SwornMembersIds []int
+	SwornMembersIds []CharacterID `json:"swornMembersIds"`
 }
 
 type house struct {
@@ -85,27 +85,27 @@ func (h house) Convert() House {
 		Words:            h.Words,
 		Titles:           h.Titles,
 		Seats:            h.Seats,
-		CurrentLordID:    h.CurrentLord.id(),
-		HeirID:           h.Heir.id(),
-		OverlordID:       h.Overlord.id(),
+		CurrentLordID:    optionalID[CharacterID](h.CurrentLord),
+		HeirID:           optionalID[CharacterID](h.Heir),
+		OverlordID:       optionalID[HouseID](h.Overlord),
 		Founded:          h.Founded,
-		FounderID:        h.Founder.id(),
+		FounderID:        optionalID[CharacterID](h.Founder),
 		DiedOut:          h.DiedOut,
 		AncestralWeapons: h.AncestralWeapons,
-		CadetBranchesIds: h.CadetBranches.ids(),
-		SwornMembersIds:  h.SwornMembers.ids(),
+		CadetBranchesIds: ids[HouseID](h.CadetBranches),
+		SwornMembersIds:  ids[CharacterID](h.SwornMembers),
 	}
 
 	return house
 }
 
 type housesResponse struct {
-	links map[string]string
+	links Links
 
 	Houses []house
 }
 
-func (housesResponse *housesResponse) Link(links map[string]string) {
+func (housesResponse *housesResponse) Link(links Links) {
 	housesResponse.links = links
 }
 
@@ -13,7 +13,17 @@ import (
 type HouseRequest interface {
 	ParamConverter
 
+	// Page sets the page of the result set to return. The first page is 1.
+	Page(int) HouseRequest
+
+	// PageSize sets the maximum houses to return per page. The api accepts
+	// a page size between MinPageSize and MaxPageSize, the default is
+	// DefaultPageSize.
+	PageSize(int) HouseRequest
+
 	// Limit sets the maximum houses to return.
+	//
+	// Deprecated: Limit is an alias for PageSize.
 	Limit(int) HouseRequest
 
 	// Name can be used to filter the returned houses by their name.
@@ -50,9 +60,7 @@ type HouseRequest interface {
 
 // NewHouseRequest returns a new HouseRequest which can be used to filter houses.
 func NewHouseRequest() HouseRequest {
-	h := houseRequest{}
-	h.limit = 10
-	return h
+	return houseRequest{request: newRequest()}
 }
 
 type houseRequest struct {
@@ -68,18 +76,23 @@ type houseRequest struct {
 	hasAncestralWeapons *bool
 }
 
-func (request houseRequest) Limit(value int) HouseRequest {
-	request.limit = value
+func (request houseRequest) Page(value int) HouseRequest {
+	request.setPage(value)
+	return request
+}
+
+func (request houseRequest) PageSize(value int) HouseRequest {
+	request.setPageSize(value)
 	return request
 }
 
+func (request houseRequest) Limit(value int) HouseRequest {
+	return request.PageSize(value)
+}
+
 func (request houseRequest) Convert() url.Values {
-	params := url.Values{}
-	params.Add("page", strconv.Itoa(request.limit))
+	params := request.params()
 
-	if request.page != nil {
-		params.Set("pageSize", strconv.Itoa(*request.page))
-	}
 	if request.name != nil {
 		params.Set("name", *request.name)
 	}
@@ -4,7 +4,10 @@
 
 package goiaf
 
-import "strconv"
+import (
+	"encoding/json"
+	"strconv"
+)
 
 // HouseResponse contains the data from the performed request.
 //
@@ -13,35 +16,62 @@ import "strconv"
 // the request will return a different result set.
 //
 // Note that, if a result set is not available these methods will return the ErrNoResultSet error.
+//
+// HouseResponse can be marshaled to and unmarshaled from JSON, the pagination links are kept.
 type HouseResponse struct {
 	// Data contains the houses from the request.
 	Data []House
 
-	links map[string]string
+	links Links
+}
+
+type houseResponseJSON struct {
+	Data  []House `json:"data"`
+	Links Links   `json:"links"`
+}
+
+// MarshalJSON makes the HouseResponse type implement the json.Marshaler interface.
+func (response HouseResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(houseResponseJSON{
+		Data:  response.Data,
+		Links: response.links,
+	})
+}
+
+// UnmarshalJSON makes the HouseResponse type implement the json.Unmarshaler interface.
+func (response *HouseResponse) UnmarshalJSON(data []byte) error {
+	r := houseResponseJSON{}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	response.Data = r.Data
+	response.links = r.Links
+	return nil
 }
 
 // Next returns a HouseRequest, which can be used to retrieve
 // the next result set of houses.
 func (response HouseResponse) Next() (HouseRequest, error) {
-	return response.getRequestForURL(response.links["next"])
+	return response.getRequestForURL(response.links.URL("next"))
 }
 
 // Prev returns a HouseRequest, which can be used to retrieve
 // the previous result set of houses.
 func (response HouseResponse) Prev() (HouseRequest, error) {
-	return response.getRequestForURL(response.links["prev"])
+	return response.getRequestForURL(response.links.URL("prev"))
 }
 
 // First returns a HouseRequest, which can be used to retrieve
 // the last result set of houses.
 func (response HouseResponse) First() (HouseRequest, error) {
-	return response.getRequestForURL(response.links["first"])
+	return response.getRequestForURL(response.links.URL("first"))
 }
 
 // Last returns a HouseRequest, which can be used to retrieve
 // the first result set of houses.
 func (response HouseResponse) Last() (HouseRequest, error) {
-	return response.getRequestForURL(response.links["last"])
+	return response.getRequestForURL(response.links.URL("last"))
 }
 
 func (response HouseResponse) getRequestForURL(urlStr string) (HouseRequest, error) {
@@ -56,8 +86,8 @@ func (response HouseResponse) getRequestForURL(urlStr string) (HouseRequest, err
 	}
 
 	request := houseRequest{}
-	request.limit = page
-	request.page = &pageSize
+	request.setPage(page)
+	request.setPageSize(pageSize)
 
 	if value := query.Get("name"); value != "" {
 		request.name = &value
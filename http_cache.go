@@ -0,0 +1,181 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithHTTPCache enables caching of responses based on the cache headers sent
+// by the api. Responses are stored together with their ETag, Last-Modified
+// and Cache-Control max-age values. A fresh response is served without a
+// request, a stale one is revalidated with a conditional request and served
+// from the cache if the api responds with 304 Not Modified.
+//
+// The responses are kept in an in-memory LRUCache, use WithCache to store
+// them elsewhere.
+func WithHTTPCache() Option {
+	return WithCache(NewLRUCache(defaultCacheSize), 0)
+}
+
+// cacheEntry is a cached response for a single URL.
+type cacheEntry struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	Link         string
+
+	// Expires is the time until the entry is fresh. A zero value means that
+	// the entry always has to be revalidated.
+	Expires time.Time
+}
+
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return !e.Expires.IsZero() && now.Before(e.Expires)
+}
+
+func (e *cacheEntry) revalidatable() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+func (e *cacheEntry) response() *response {
+	header := http.Header{}
+	if e.Link != "" {
+		header.Set("Link", e.Link)
+	}
+
+	return &response{header: header, body: e.Body}
+}
+
+// setRequestHeaders makes the request conditional on the validators of the entry.
+func (e *cacheEntry) setRequestHeaders(req *http.Request) {
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// update refreshes the entry from the headers of a response. If the
+// response has no max-age, the entry is fresh for the given ttl.
+func (e *cacheEntry) update(header http.Header, now time.Time, ttl time.Duration) {
+	if etag := header.Get("ETag"); etag != "" {
+		e.ETag = etag
+	}
+	if lastModified := header.Get("Last-Modified"); lastModified != "" {
+		e.LastModified = lastModified
+	}
+	if link := header.Get("Link"); link != "" {
+		e.Link = link
+	}
+
+	cacheControl := header.Get("Cache-Control")
+
+	e.Expires = time.Time{}
+	if maxAge, ok := maxAge(cacheControl); ok {
+		e.Expires = now.Add(maxAge)
+	} else if ttl > 0 && !hasDirective(cacheControl, "no-cache") {
+		e.Expires = now.Add(ttl)
+	}
+}
+
+// httpCache stores cache entries in a Cache.
+type httpCache struct {
+	cache Cache
+	ttl   time.Duration
+}
+
+func (hc *httpCache) get(key string) *cacheEntry {
+	b, ok := hc.cache.Get(key)
+	if !ok {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(b, entry); err != nil {
+		hc.cache.Delete(key)
+		return nil
+	}
+
+	return entry
+}
+
+// set stores the entry. Entries which can be revalidated are kept until they
+// are evicted, other entries are dropped as soon as they are no longer fresh.
+func (hc *httpCache) set(key string, entry *cacheEntry, now time.Time) {
+	var ttl time.Duration
+	if !entry.revalidatable() {
+		if !entry.fresh(now) {
+			hc.cache.Delete(key)
+			return
+		}
+		ttl = entry.Expires.Sub(now)
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	hc.cache.Set(key, b, ttl)
+}
+
+// store caches a response from the api, unless its headers forbid it.
+func (hc *httpCache) store(key string, resp *response, now time.Time) {
+	if hasDirective(resp.header.Get("Cache-Control"), "no-store") {
+		hc.cache.Delete(key)
+		return
+	}
+
+	entry := &cacheEntry{Body: resp.body}
+	entry.update(resp.header, now, hc.ttl)
+	hc.set(key, entry, now)
+}
+
+// revalidated refreshes a cached entry after a 304 Not Modified response.
+func (hc *httpCache) revalidated(key string, entry *cacheEntry, resp *response, now time.Time) {
+	entry.update(resp.header, now, hc.ttl)
+	hc.set(key, entry, now)
+}
+
+// maxAge returns the max-age directive of a Cache-Control header. A
+// no-cache directive takes precedence over max-age.
+func maxAge(cacheControl string) (time.Duration, bool) {
+	var (
+		age time.Duration
+		ok  bool
+	)
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		if directive == "no-cache" {
+			return 0, false
+		}
+		if strings.HasPrefix(directive, "max-age=") {
+			seconds, err := strconv.Atoi(strings.Trim(directive[len("max-age="):], `"`))
+			if err == nil && seconds > 0 {
+				age, ok = time.Duration(seconds)*time.Second, true
+			}
+		}
+	}
+
+	return age, ok
+}
+
+func hasDirective(cacheControl, name string) bool {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		if strings.ToLower(strings.TrimSpace(directive)) == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,209 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cacheServer serves a page of characters with the given cache headers and
+// answers conditional requests with 304 Not Modified.
+type cacheServer struct {
+	*httptest.Server
+
+	etag         string
+	lastModified string
+	cacheControl string
+
+	mu          sync.Mutex
+	requests    int
+	notModified int
+	conditional []string
+}
+
+func newCacheServer(t *testing.T, etag, lastModified, cacheControl string) *cacheServer {
+	s := &cacheServer{etag: etag, lastModified: lastModified, cacheControl: cacheControl}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *cacheServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	s.conditional = append(s.conditional, r.Header.Get("If-None-Match")+"|"+r.Header.Get("If-Modified-Since"))
+
+	if s.cacheControl != "" {
+		w.Header().Set("Cache-Control", s.cacheControl)
+	}
+	if (s.etag != "" && r.Header.Get("If-None-Match") == s.etag) ||
+		(s.lastModified != "" && r.Header.Get("If-Modified-Since") == s.lastModified) {
+		// A 304 response does not repeat the link header.
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	if s.lastModified != "" {
+		w.Header().Set("Last-Modified", s.lastModified)
+	}
+	character := fmt.Sprintf(`{"url":"%s/api/characters/1","name":"Jon Snow"}`, s.URL)
+	if !strings.HasSuffix(r.URL.Path, "/characters") {
+		fmt.Fprint(w, character)
+		return
+	}
+	w.Header().Set("Link", fmt.Sprintf(`<%s/api/characters?page=2&pageSize=1>; rel="next", <%s/api/characters?page=3&pageSize=1>; rel="last"`, s.URL, s.URL))
+	fmt.Fprintf(w, "[%s]", character)
+}
+
+func (s *cacheServer) counts() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests, s.notModified
+}
+
+func (s *cacheServer) client(opts ...Option) Client {
+	return NewClient(append([]Option{WithBaseURL(s.URL + "/api")}, opts...)...)
+}
+
+func TestHTTPCacheRevalidation(t *testing.T) {
+	server := newCacheServer(t, `"v1"`, "", "")
+	client := server.client(WithHTTPCache())
+
+	for i := 0; i < 3; i++ {
+		response, err := client.Characters(NewCharacterRequest().PageSize(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Data) != 1 || response.Data[0].Name != "Jon Snow" {
+			t.Fatalf("request %d returned %+v, want Jon Snow", i+1, response.Data)
+		}
+
+		// The link header of the cached response must survive the 304.
+		next, err := response.Next()
+		if err != nil {
+			t.Fatalf("request %d: Next returned %v", i+1, err)
+		}
+		if page := pageOf(next); page != 2 {
+			t.Errorf("request %d: Next is page %d, want 2", i+1, page)
+		}
+	}
+
+	requests, notModified := server.counts()
+	if requests != 3 || notModified != 2 {
+		t.Errorf("server received %d requests with %d revalidations, want 3 and 2", requests, notModified)
+	}
+	if server.conditional[1] != `"v1"|` {
+		t.Errorf("revalidation sent %q, want If-None-Match \"v1\"", server.conditional[1])
+	}
+}
+
+func TestHTTPCacheLastModified(t *testing.T) {
+	lastModified := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+	server := newCacheServer(t, "", lastModified, "")
+	client := server.client(WithHTTPCache())
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Character(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, notModified := server.counts(); notModified != 1 {
+		t.Errorf("server answered %d revalidations, want 1", notModified)
+	}
+	if server.conditional[1] != "|"+lastModified {
+		t.Errorf("revalidation sent %q, want If-Modified-Since %s", server.conditional[1], lastModified)
+	}
+}
+
+func TestHTTPCacheMaxAge(t *testing.T) {
+	server := newCacheServer(t, `"v1"`, "", "public, max-age=60")
+	client := server.client(WithHTTPCache())
+
+	for i := 0; i < 3; i++ {
+		response, err := client.Characters(NewCharacterRequest().PageSize(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := response.Last(); err != nil {
+			t.Errorf("request %d: Last returned %v from a fresh cached response", i+1, err)
+		}
+	}
+
+	if requests, _ := server.counts(); requests != 1 {
+		t.Errorf("server received %d requests, want 1 while the response is fresh", requests)
+	}
+}
+
+func TestHTTPCacheNoStore(t *testing.T) {
+	server := newCacheServer(t, `"v1"`, "", "no-store")
+	cache := NewLRUCache(10)
+	client := server.client(WithCache(cache, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Character(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	requests, notModified := server.counts()
+	if requests != 2 || notModified != 0 {
+		t.Errorf("server received %d requests with %d revalidations, want 2 and 0", requests, notModified)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("cache holds %d entries, want none for no-store responses", cache.Len())
+	}
+}
+
+func TestHTTPCacheTTL(t *testing.T) {
+	server := newCacheServer(t, "", "", "")
+	client := server.client(WithCache(NewLRUCache(10), time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Character(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if requests, _ := server.counts(); requests != 1 {
+		t.Errorf("server received %d requests, want 1 within the ttl", requests)
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	tests := []struct {
+		header string
+		age    time.Duration
+		ok     bool
+	}{
+		{"max-age=60", time.Minute, true},
+		{"public, MAX-AGE=\"10\"", 10 * time.Second, true},
+		{"max-age=0", 0, false},
+		{"max-age=60, no-cache", 0, false},
+		{"private", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		age, ok := maxAge(test.header)
+		if age != test.age || ok != test.ok {
+			t.Errorf("maxAge(%q) = %v, %v, want %v, %v", test.header, age, ok, test.age, test.ok)
+		}
+	}
+}
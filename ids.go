@@ -0,0 +1,52 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import "strconv"
+
+// BookID is the id of a book resource.
+type BookID int
+
+// CharacterID is the id of a character resource.
+type CharacterID int
+
+// HouseID is the id of a house resource.
+type HouseID int
+
+// String makes the BookID type implement the fmt.Stringer interface.
+func (id BookID) String() string {
+	return strconv.Itoa(int(id))
+}
+
+// String makes the CharacterID type implement the fmt.Stringer interface.
+func (id CharacterID) String() string {
+	return strconv.Itoa(int(id))
+}
+
+// String makes the HouseID type implement the fmt.Stringer interface.
+func (id HouseID) String() string {
+	return strconv.Itoa(int(id))
+}
+
+// ID returns the id of the book, taken from its URL, and whether the URL
+// ends with a valid id.
+func (b Book) ID() (BookID, bool) {
+	id, err := parseID(b.URL)
+	return BookID(id), err == nil
+}
+
+// ID returns the id of the character, taken from its URL, and whether the
+// URL ends with a valid id.
+func (c Character) ID() (CharacterID, bool) {
+	id, err := parseID(c.URL)
+	return CharacterID(id), err == nil
+}
+
+// ID returns the id of the house, taken from its URL, and whether the URL
+// ends with a valid id.
+func (h House) ID() (HouseID, bool) {
+	id, err := parseID(h.URL)
+	return HouseID(id), err == nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResourceID(t *testing.T) {
+	tests := []struct {
+		url string
+		id  CharacterID
+		ok  bool
+	}{
+		{"http://www.anapioficeandfire.com/api/characters/583", 583, true},
+		{"583", 583, true},
+		{"", 0, false},
+		{"http://www.anapioficeandfire.com/api/characters/", 0, false},
+		{"http://www.anapioficeandfire.com/api/characters/0", 0, false},
+		{"http://www.anapioficeandfire.com/api/characters/-1", 0, false},
+		{"http://www.anapioficeandfire.com/api/characters/jon", 0, false},
+	}
+
+	for _, test := range tests {
+		id, ok := Character{URL: test.url}.ID()
+		if id != test.id || ok != test.ok {
+			t.Errorf("Character{URL: %q}.ID() = %d, %v, want %d, %v", test.url, id, ok, test.id, test.ok)
+		}
+	}
+}
+
+func TestOptionalIDsFromJSON(t *testing.T) {
+	var c character
+	err := json.Unmarshal([]byte(`{"url":"http://www.anapioficeandfire.com/api/characters/2","father":"","mother":"http://www.anapioficeandfire.com/api/characters/3"}`), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	converted := c.Convert()
+	if converted.FatherID != nil {
+		t.Errorf("FatherID = %d, want nil", *converted.FatherID)
+	}
+	if converted.MotherID == nil || *converted.MotherID != 3 {
+		t.Errorf("MotherID = %v, want 3", converted.MotherID)
+	}
+
+	err = json.Unmarshal([]byte(`{"url":"http://www.anapioficeandfire.com/api/characters/2","father":"http://www.anapioficeandfire.com/api/characters/x"}`), &c)
+	if err == nil {
+		t.Error("decoding an invalid father URL succeeded, want error")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package testfixture builds the characters and houses used by the tests of
+// the genealogy, gedcom, diagram and separation packages.
+package testfixture
+
+import (
+	"fmt"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+// BaseURL is the base URL of the resources built by the package.
+const BaseURL = "https://anapioficeandfire.com/api"
+
+// BookURL returns the URL of the book with the id.
+func BookURL(id int) string {
+	return fmt.Sprintf("%s/books/%d", BaseURL, id)
+}
+
+// CharacterURL returns the URL of the character with the id.
+func CharacterURL(id int) string {
+	return fmt.Sprintf("%s/characters/%d", BaseURL, id)
+}
+
+// HouseURL returns the URL of the house with the id.
+func HouseURL(id int) string {
+	return fmt.Sprintf("%s/houses/%d", BaseURL, id)
+}
+
+// CharacterRef returns a reference to the character with the id, or nil
+// if the id is zero.
+func CharacterRef(id int) *goiaf.CharacterID {
+	if id == 0 {
+		return nil
+	}
+
+	characterID := goiaf.CharacterID(id)
+	return &characterID
+}
+
+// HouseRef returns a reference to the house with the id, or nil if the id
+// is zero.
+func HouseRef(id int) *goiaf.HouseID {
+	if id == 0 {
+		return nil
+	}
+
+	houseID := goiaf.HouseID(id)
+	return &houseID
+}
+
+// Character returns a character with the given id and gender. A father,
+// mother or spouse of zero means that the character has none.
+func Character(id int, name, gender string, father, mother, spouse int) goiaf.Character {
+	return goiaf.Character{
+		URL:      CharacterURL(id),
+		Name:     name,
+		Gender:   gender,
+		FatherID: CharacterRef(father),
+		MotherID: CharacterRef(mother),
+		SpouseID: CharacterRef(spouse),
+		Titles:   []string{},
+		Aliases:  []string{},
+	}
+}
+
+// House returns a house with the given id, overlord and cadet branches. An
+// overlord of zero means that the house has none.
+func House(id int, name string, overlord int, cadets ...goiaf.HouseID) goiaf.House {
+	return goiaf.House{
+		URL:              HouseURL(id),
+		Name:             name,
+		OverlordID:       HouseRef(overlord),
+		CadetBranchesIds: cadets,
+	}
+}
+
+// Starks returns five generations of a family, and Hodor, who is not
+// related to any of them:
+//
+//	1 Rickard + 2 Lyarra
+//	├── 3 Eddard + 4 Catelyn
+//	│   ├── 6 Robb
+//	│   │   └── 11 Rickon, without gender
+//	│   ├── 7 Sansa
+//	│   └── 8 Jon (Eddard only)
+//	└── 5 Brandon
+//	    └── 12 Edric
+//	        └── 13 Beron
+//	            └── 14 Lyanne
+//	20 Hodor
+func Starks() []goiaf.Character {
+	return []goiaf.Character{
+		Character(1, "Rickard Stark", "Male", 0, 0, 2),
+		Character(2, "Lyarra Stark", "Female", 0, 0, 0),
+		Character(3, "Eddard Stark", "Male", 1, 2, 4),
+		Character(4, "Catelyn Tully", "Female", 0, 0, 3),
+		Character(5, "Brandon Stark", "Male", 1, 2, 0),
+		Character(6, "Robb Stark", "Male", 3, 4, 0),
+		Character(7, "Sansa Stark", "Female", 3, 4, 0),
+		Character(8, "Jon Snow", "Male", 3, 0, 0),
+		Character(11, "Rickon", "", 6, 0, 0),
+		Character(12, "Edric Stark", "Male", 5, 0, 0),
+		Character(13, "Beron Stark", "Male", 12, 0, 0),
+		Character(14, "Lyanne Stark", "Female", 13, 0, 0),
+		Character(20, "Hodor", "Male", 0, 0, 0),
+	}
+}
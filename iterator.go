@@ -0,0 +1,319 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"iter"
+)
+
+// BookIterator iterates over all books matching a BookRequest. It follows
+// the pagination of the api, fetching the next result set when the current
+// one is exhausted.
+//
+//	it := goiaf.NewBookIterator(ctx, client, goiaf.NewBookRequest())
+//	for it.Next() {
+//		book := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type BookIterator struct {
+	ctx     context.Context
+	client  Client
+	request BookRequest
+
+	page  []Book
+	index int
+	value Book
+	err   error
+
+	// nextErr is an error which occurred while reading the pagination
+	// of the current result set. It is reported once the result set
+	// has been iterated.
+	nextErr error
+}
+
+// NewBookIterator returns a BookIterator over all books matching the request.
+// A nil request returns all books.
+func NewBookIterator(ctx context.Context, client Client, request BookRequest) *BookIterator {
+	if request == nil {
+		request = NewBookRequest()
+	}
+
+	return &BookIterator{
+		ctx:     ctx,
+		client:  client,
+		request: request,
+	}
+}
+
+// Next advances the iterator to the next book, which is then available
+// through Value. It returns false when there are no more books or an
+// error occurred.
+func (it *BookIterator) Next() bool {
+	for it.index >= len(it.page) {
+		if it.err != nil {
+			return false
+		}
+		if it.request == nil {
+			it.err = it.nextErr
+			return false
+		}
+
+		response, err := it.client.BooksContext(it.ctx, it.request)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.index = response.Data, 0
+
+		it.request, err = response.Next()
+		if err != nil {
+			it.request = nil
+			if err != ErrNoResultSet {
+				it.nextErr = err
+			}
+		}
+	}
+
+	it.value = it.page[it.index]
+	it.index++
+	return true
+}
+
+// Value returns the current book.
+func (it *BookIterator) Value() Book {
+	return it.value
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *BookIterator) Err() error {
+	return it.err
+}
+
+// AllBooks returns an iterator over all books matching the request, for use
+// with a range loop. If an error occurs, it is yielded as the last element.
+// A nil request returns all books.
+func AllBooks(ctx context.Context, client Client, request BookRequest) iter.Seq2[Book, error] {
+	return func(yield func(Book, error) bool) {
+		it := NewBookIterator(ctx, client, request)
+		for it.Next() {
+			if !yield(it.Value(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(Book{}, err)
+		}
+	}
+}
+
+// CharacterIterator iterates over all characters matching a CharacterRequest. It follows
+// the pagination of the api, fetching the next result set when the current
+// one is exhausted.
+//
+//	it := goiaf.NewCharacterIterator(ctx, client, goiaf.NewCharacterRequest())
+//	for it.Next() {
+//		character := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type CharacterIterator struct {
+	ctx     context.Context
+	client  Client
+	request CharacterRequest
+
+	page  []Character
+	index int
+	value Character
+	err   error
+
+	// nextErr is an error which occurred while reading the pagination
+	// of the current result set. It is reported once the result set
+	// has been iterated.
+	nextErr error
+}
+
+// NewCharacterIterator returns a CharacterIterator over all characters matching the request.
+// A nil request returns all characters.
+func NewCharacterIterator(ctx context.Context, client Client, request CharacterRequest) *CharacterIterator {
+	if request == nil {
+		request = NewCharacterRequest()
+	}
+
+	return &CharacterIterator{
+		ctx:     ctx,
+		client:  client,
+		request: request,
+	}
+}
+
+// Next advances the iterator to the next character, which is then available
+// through Value. It returns false when there are no more characters or an
+// error occurred.
+func (it *CharacterIterator) Next() bool {
+	for it.index >= len(it.page) {
+		if it.err != nil {
+			return false
+		}
+		if it.request == nil {
+			it.err = it.nextErr
+			return false
+		}
+
+		response, err := it.client.CharactersContext(it.ctx, it.request)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.index = response.Data, 0
+
+		it.request, err = response.Next()
+		if err != nil {
+			it.request = nil
+			if err != ErrNoResultSet {
+				it.nextErr = err
+			}
+		}
+	}
+
+	it.value = it.page[it.index]
+	it.index++
+	return true
+}
+
+// Value returns the current character.
+func (it *CharacterIterator) Value() Character {
+	return it.value
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *CharacterIterator) Err() error {
+	return it.err
+}
+
+// AllCharacters returns an iterator over all characters matching the request, for use
+// with a range loop. If an error occurs, it is yielded as the last element.
+// A nil request returns all characters.
+func AllCharacters(ctx context.Context, client Client, request CharacterRequest) iter.Seq2[Character, error] {
+	return func(yield func(Character, error) bool) {
+		it := NewCharacterIterator(ctx, client, request)
+		for it.Next() {
+			if !yield(it.Value(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(Character{}, err)
+		}
+	}
+}
+
+// HouseIterator iterates over all houses matching a HouseRequest. It follows
+// the pagination of the api, fetching the next result set when the current
+// one is exhausted.
+//
+//	it := goiaf.NewHouseIterator(ctx, client, goiaf.NewHouseRequest())
+//	for it.Next() {
+//		house := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type HouseIterator struct {
+	ctx     context.Context
+	client  Client
+	request HouseRequest
+
+	page  []House
+	index int
+	value House
+	err   error
+
+	// nextErr is an error which occurred while reading the pagination
+	// of the current result set. It is reported once the result set
+	// has been iterated.
+	nextErr error
+}
+
+// NewHouseIterator returns a HouseIterator over all houses matching the request.
+// A nil request returns all houses.
+func NewHouseIterator(ctx context.Context, client Client, request HouseRequest) *HouseIterator {
+	if request == nil {
+		request = NewHouseRequest()
+	}
+
+	return &HouseIterator{
+		ctx:     ctx,
+		client:  client,
+		request: request,
+	}
+}
+
+// Next advances the iterator to the next house, which is then available
+// through Value. It returns false when there are no more houses or an
+// error occurred.
+func (it *HouseIterator) Next() bool {
+	for it.index >= len(it.page) {
+		if it.err != nil {
+			return false
+		}
+		if it.request == nil {
+			it.err = it.nextErr
+			return false
+		}
+
+		response, err := it.client.HousesContext(it.ctx, it.request)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.index = response.Data, 0
+
+		it.request, err = response.Next()
+		if err != nil {
+			it.request = nil
+			if err != ErrNoResultSet {
+				it.nextErr = err
+			}
+		}
+	}
+
+	it.value = it.page[it.index]
+	it.index++
+	return true
+}
+
+// Value returns the current house.
+func (it *HouseIterator) Value() House {
+	return it.value
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *HouseIterator) Err() error {
+	return it.err
+}
+
+// AllHouses returns an iterator over all houses matching the request, for use
+// with a range loop. If an error occurs, it is yielded as the last element.
+// A nil request returns all houses.
+func AllHouses(ctx context.Context, client Client, request HouseRequest) iter.Seq2[House, error] {
+	return func(yield func(House, error) bool) {
+		it := NewHouseIterator(ctx, client, request)
+		for it.Next() {
+			if !yield(it.Value(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(House{}, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/goiaftest"
+)
+
+func TestBookIterator(t *testing.T) {
+	server := newTestServer(t)
+
+	it := goiaf.NewBookIterator(context.Background(), server.Client(), goiaf.NewBookRequest().PageSize(1))
+	names := []string{}
+	for it.Next() {
+		names = append(names, it.Value().Name)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(names) != "[Book 1 Book 2 Book 3]" {
+		t.Errorf("iterated %v, want Book 1 to Book 3", names)
+	}
+	if server.Requests() != 3 {
+		t.Errorf("server received %d requests, want 3 for 3 pages", server.Requests())
+	}
+}
+
+func TestCharacterIterator(t *testing.T) {
+	server := newTestServer(t)
+
+	it := goiaf.NewCharacterIterator(context.Background(), server.Client(), goiaf.NewCharacterRequest().PageSize(10))
+	n := 0
+	for it.Next() {
+		n++
+		if want := fmt.Sprintf("Character %d", n); it.Value().Name != want {
+			t.Errorf("character %d = %q, want %q", n, it.Value().Name, want)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 25 || server.Requests() != 3 {
+		t.Errorf("iterated %d characters with %d requests, want 25 and 3", n, server.Requests())
+	}
+}
+
+func TestHouseIterator(t *testing.T) {
+	server := newTestServer(t)
+
+	it := goiaf.NewHouseIterator(context.Background(), server.Client(), nil)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 4 || server.Requests() != 1 {
+		t.Errorf("iterated %d houses with %d requests, want 4 and 1", n, server.Requests())
+	}
+}
+
+func TestCharacterIteratorError(t *testing.T) {
+	server := newTestServer(t)
+
+	it := goiaf.NewCharacterIterator(context.Background(), server.Client(), goiaf.NewCharacterRequest().PageSize(10))
+	n := 0
+	for it.Next() {
+		n++
+		if n == 10 {
+			server.AddFault(goiaftest.Fault{PathPrefix: "/api/characters", StatusCode: http.StatusBadRequest})
+		}
+	}
+
+	var apiErr *goiaf.APIError
+	if !errors.As(it.Err(), &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Err() = %v, want an APIError with status 400", it.Err())
+	}
+	if n != 10 {
+		t.Errorf("iterated %d characters before the error, want the 10 of the first page", n)
+	}
+	if it.Next() {
+		t.Error("Next() returned true after an error")
+	}
+	if server.Requests() != 2 {
+		t.Errorf("server received %d requests, want 2", server.Requests())
+	}
+}
+
+func TestAllBooks(t *testing.T) {
+	server := newTestServer(t)
+
+	names := []string{}
+	for book, err := range goiaf.AllBooks(context.Background(), server.Client(), goiaf.NewBookRequest().PageSize(2)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, book.Name)
+	}
+
+	if fmt.Sprint(names) != "[Book 1 Book 2 Book 3]" {
+		t.Errorf("iterated %v, want Book 1 to Book 3", names)
+	}
+	if server.Requests() != 2 {
+		t.Errorf("server received %d requests, want 2", server.Requests())
+	}
+}
+
+func TestAllCharactersError(t *testing.T) {
+	server := newTestServer(t)
+
+	n := 0
+	var errs []error
+	for _, err := range goiaf.AllCharacters(context.Background(), server.Client(), goiaf.NewCharacterRequest().PageSize(10)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n++
+		if n == 20 {
+			server.AddFault(goiaftest.Fault{PathPrefix: "/api/characters", StatusCode: http.StatusNotFound})
+		}
+	}
+
+	if n != 20 || len(errs) != 1 || !errors.Is(errs[0], goiaf.ErrResourceNotFound) {
+		t.Errorf("iterated %d characters and errors %v, want 20 and a single ErrResourceNotFound", n, errs)
+	}
+	if server.Requests() != 3 {
+		t.Errorf("server received %d requests, want 3", server.Requests())
+	}
+}
+
+func TestAllHousesBreak(t *testing.T) {
+	server := newTestServer(t)
+
+	n := 0
+	for _, err := range goiaf.AllHouses(context.Background(), server.Client(), goiaf.NewHouseRequest().PageSize(1)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+		if n == 2 {
+			break
+		}
+	}
+
+	if server.Requests() != 2 {
+		t.Errorf("server received %d requests after breaking on the second page, want 2", server.Requests())
+	}
+}
+
+func TestAllCharactersBreakWithinPage(t *testing.T) {
+	server := newTestServer(t)
+
+	for range goiaf.AllCharacters(context.Background(), server.Client(), goiaf.NewCharacterRequest().PageSize(10)) {
+		break
+	}
+
+	if server.Requests() != 1 {
+		t.Errorf("server received %d requests after breaking on the first character, want 1", server.Requests())
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Link is a single link of a link header, as described by RFC 8288.
+type Link struct {
+	// The target URL of the link, resolved against the request URL.
+	URL string `json:"url"`
+
+	// The relation types of the link, in lower case, e.g. next or last.
+	Rel []string `json:"rel"`
+
+	// The parameters of the link, keyed by their lower case name. The
+	// rel parameter is included as well.
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Links are the links of a link header.
+type Links []Link
+
+// Get returns the first link with the given relation type.
+func (l Links) Get(rel string) (Link, bool) {
+	rel = strings.ToLower(rel)
+	for _, link := range l {
+		for _, r := range link.Rel {
+			if r == rel {
+				return link, true
+			}
+		}
+	}
+
+	return Link{}, false
+}
+
+// URL returns the URL of the first link with the given relation type,
+// or an empty string if there is no such link.
+func (l Links) URL(rel string) string {
+	link, _ := l.Get(rel)
+	return link.URL
+}
+
+// ParseLinks parses the value of a link header according to RFC 8288. Relative
+// link targets are resolved against base, if it is not nil. Parameters may be
+// tokens or quoted strings, and a rel parameter may hold several space
+// separated relation types. An empty header returns no links.
+func ParseLinks(header string, base *url.URL) (Links, error) {
+	p := linkParser{s: header}
+	links := Links{}
+
+	for {
+		p.skipSeparators()
+		if p.done() {
+			return links, nil
+		}
+
+		link, err := p.link(base)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid link header at offset %d: %v", p.i, err)
+		}
+		links = append(links, link)
+
+		p.skipSpace()
+		if !p.done() && p.peek() != ',' {
+			return nil, fmt.Errorf("Invalid link header at offset %d: unexpected %q", p.i, p.peek())
+		}
+	}
+}
+
+type linkParser struct {
+	s string
+	i int
+}
+
+func (p *linkParser) done() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *linkParser) peek() byte {
+	return p.s[p.i]
+}
+
+func (p *linkParser) skipSpace() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.i++
+	}
+}
+
+// skipSeparators skips whitespace and empty list elements.
+func (p *linkParser) skipSeparators() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == ',') {
+		p.i++
+	}
+}
+
+func (p *linkParser) link(base *url.URL) (Link, error) {
+	if p.peek() != '<' {
+		return Link{}, fmt.Errorf("expected '<', got %q", p.peek())
+	}
+	end := strings.IndexByte(p.s[p.i:], '>')
+	if end < 0 {
+		return Link{}, fmt.Errorf("missing '>'")
+	}
+	target := strings.TrimSpace(p.s[p.i+1 : p.i+end])
+	p.i += end + 1
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return Link{}, err
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+
+	link := Link{
+		URL:    u.String(),
+		Params: map[string]string{},
+	}
+
+	for {
+		p.skipSpace()
+		if p.done() || p.peek() != ';' {
+			break
+		}
+		p.i++
+		p.skipSpace()
+
+		name, value, err := p.param()
+		if err != nil {
+			return Link{}, err
+		}
+		if name == "" {
+			// An empty parameter, e.g. a trailing semicolon.
+			continue
+		}
+		// Only the first occurrence of a parameter is used.
+		if _, ok := link.Params[name]; !ok {
+			link.Params[name] = value
+		}
+	}
+
+	if rel, ok := link.Params["rel"]; ok {
+		link.Rel = strings.Fields(strings.ToLower(rel))
+	}
+
+	return link, nil
+}
+
+func (p *linkParser) param() (string, string, error) {
+	name := strings.ToLower(p.token())
+	p.skipSpace()
+	if p.done() || p.peek() != '=' {
+		return name, "", nil
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("parameter without name")
+	}
+	p.i++
+	p.skipSpace()
+
+	if !p.done() && p.peek() == '"' {
+		value, err := p.quoted()
+		return name, value, err
+	}
+
+	return name, p.token(), nil
+}
+
+// token reads characters up to a delimiter of the link header.
+func (p *linkParser) token() string {
+	start := p.i
+	for !p.done() && !strings.ContainsRune(" \t;,=\"<>", rune(p.peek())) {
+		p.i++
+	}
+
+	return p.s[start:p.i]
+}
+
+// quoted reads a quoted string, unescaping quoted pairs.
+func (p *linkParser) quoted() (string, error) {
+	p.i++
+
+	var b strings.Builder
+	for !p.done() {
+		c := p.peek()
+		p.i++
+
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if p.done() {
+				return "", fmt.Errorf("unterminated quoted string")
+			}
+			b.WriteByte(p.peek())
+			p.i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", fmt.Errorf("unterminated quoted string")
+}
@@ -0,0 +1,172 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLinks(t *testing.T) {
+	base, _ := url.Parse("http://www.anapioficeandfire.com/api/characters?page=2")
+
+	tests := []struct {
+		name   string
+		header string
+		want   Links
+	}{
+		{
+			name:   "empty",
+			header: "",
+			want:   Links{},
+		},
+		{
+			name:   "api header",
+			header: `<http://www.anapioficeandfire.com/api/characters?page=3&pageSize=10>; rel="next", <http://www.anapioficeandfire.com/api/characters?page=1&pageSize=10>; rel="prev"`,
+			want: Links{
+				{URL: "http://www.anapioficeandfire.com/api/characters?page=3&pageSize=10", Rel: []string{"next"}, Params: map[string]string{"rel": "next"}},
+				{URL: "http://www.anapioficeandfire.com/api/characters?page=1&pageSize=10", Rel: []string{"prev"}, Params: map[string]string{"rel": "prev"}},
+			},
+		},
+		{
+			name:   "several params",
+			header: `<http://example.com/a>; rel=next; title="Next page"; type=text/html`,
+			want: Links{
+				{URL: "http://example.com/a", Rel: []string{"next"}, Params: map[string]string{"rel": "next", "title": "Next page", "type": "text/html"}},
+			},
+		},
+		{
+			name:   "several rel values",
+			header: `<http://example.com/a>; rel="Next LAST"`,
+			want: Links{
+				{URL: "http://example.com/a", Rel: []string{"next", "last"}, Params: map[string]string{"rel": "Next LAST"}},
+			},
+		},
+		{
+			name:   "quoted comma",
+			header: `<http://example.com/a>; title="a, b; c"; rel=next, <http://example.com/b>; rel=last`,
+			want: Links{
+				{URL: "http://example.com/a", Rel: []string{"next"}, Params: map[string]string{"title": "a, b; c", "rel": "next"}},
+				{URL: "http://example.com/b", Rel: []string{"last"}, Params: map[string]string{"rel": "last"}},
+			},
+		},
+		{
+			name:   "escaped quote",
+			header: `<http://example.com/a>; title="say \"hi\""`,
+			want: Links{
+				{URL: "http://example.com/a", Params: map[string]string{"title": `say "hi"`}},
+			},
+		},
+		{
+			name:   "missing rel",
+			header: `<http://example.com/a>; title=x`,
+			want: Links{
+				{URL: "http://example.com/a", Params: map[string]string{"title": "x"}},
+			},
+		},
+		{
+			name:   "relative URL",
+			header: `</api/characters?page=3>; rel=next, <?page=1>; rel=first`,
+			want: Links{
+				{URL: "http://www.anapioficeandfire.com/api/characters?page=3", Rel: []string{"next"}, Params: map[string]string{"rel": "next"}},
+				{URL: "http://www.anapioficeandfire.com/api/characters?page=1", Rel: []string{"first"}, Params: map[string]string{"rel": "first"}},
+			},
+		},
+		{
+			name:   "first parameter wins",
+			header: `<http://example.com/a>; rel=next; rel=prev;`,
+			want: Links{
+				{URL: "http://example.com/a", Rel: []string{"next"}, Params: map[string]string{"rel": "next"}},
+			},
+		},
+		{
+			name:   "empty elements",
+			header: ` , <http://example.com/a>; rel=next ,, `,
+			want: Links{
+				{URL: "http://example.com/a", Rel: []string{"next"}, Params: map[string]string{"rel": "next"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseLinks(test.header, base)
+			if err != nil {
+				t.Fatalf("ParseLinks(%q) returned error: %v", test.header, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseLinks(%q) = %#v, want %#v", test.header, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseLinksErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"unterminated quote", `<http://example.com/a>; rel="next`},
+		{"unterminated escape", `<http://example.com/a>; rel="next\`},
+		{"missing bracket", `<http://example.com/a; rel=next`},
+		{"missing target", `rel=next`},
+		{"parameter without name", `<http://example.com/a>; ="next"`},
+		{"garbage after link", `<http://example.com/a>; rel=next <http://example.com/b>`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if links, err := ParseLinks(test.header, nil); err == nil {
+				t.Errorf("ParseLinks(%q) = %#v, want error", test.header, links)
+			}
+		})
+	}
+}
+
+func TestLinksGet(t *testing.T) {
+	links, err := ParseLinks(`<http://example.com/a>; rel="prev first", <http://example.com/b>; rel=next`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := links.URL("FIRST"); got != "http://example.com/a" {
+		t.Errorf("URL(FIRST) = %q, want http://example.com/a", got)
+	}
+	if got := links.URL("next"); got != "http://example.com/b" {
+		t.Errorf("URL(next) = %q, want http://example.com/b", got)
+	}
+	if _, ok := links.Get("last"); ok {
+		t.Error("Get(last) found a link, want none")
+	}
+}
+
+func FuzzParseLinks(f *testing.F) {
+	f.Add(`<http://www.anapioficeandfire.com/api/books?page=2&pageSize=10>; rel="next", <http://www.anapioficeandfire.com/api/books?page=1&pageSize=10>; rel="first"`)
+	f.Add(`<a>; rel="Next LAST"; title="a, b"`)
+	f.Add(`<a>; rel="next`)
+	f.Add(`<a>; rel="next\`)
+	f.Add(`<a; rel=next`)
+	f.Add(`</relative?page=1>; REL=Prev;;`)
+	f.Add(`, ,<>;=`)
+
+	base, _ := url.Parse("http://www.anapioficeandfire.com/api/books")
+
+	f.Fuzz(func(t *testing.T, header string) {
+		links, err := ParseLinks(header, base)
+		if err != nil {
+			return
+		}
+
+		for _, link := range links {
+			for _, rel := range link.Rel {
+				if rel != strings.ToLower(rel) {
+					t.Errorf("ParseLinks(%q) returned rel %q, want lower case", header, rel)
+				}
+			}
+		}
+	})
+}
@@ -5,5 +5,5 @@
 package goiaf
 
 type linker interface {
-	Link(map[string]string)
+	Link(Links)
 }
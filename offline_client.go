@@ -0,0 +1,378 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type offlineClient struct {
+	books      []Book
+	characters []Character
+	houses     []House
+
+	bookIndex      map[BookID]Book
+	characterIndex map[CharacterID]Character
+	houseIndex     map[HouseID]House
+
+	booksEndpoint      string
+	charactersEndpoint string
+	housesEndpoint     string
+}
+
+// NewOfflineClient returns a client which serves the given books, characters
+// and houses from memory, without any network requests. It applies the same
+// filters as the api and returns result sets whose pagination behaves like
+// the pagination of the api, so code written against the Client interface
+// runs unchanged on a local dataset, e.g. a snapshot.
+//
+// Resources are identified by the id at the end of their URL and are
+// returned ordered by id, like the api does. Resources without a valid id
+// are left out.
+func NewOfflineClient(books []Book, characters []Character, houses []House) Client {
+	c := &offlineClient{
+		bookIndex:          map[BookID]Book{},
+		characterIndex:     map[CharacterID]Character{},
+		houseIndex:         map[HouseID]House{},
+		booksEndpoint:      defaultBaseURL + "/books",
+		charactersEndpoint: defaultBaseURL + "/characters",
+		housesEndpoint:     defaultBaseURL + "/houses",
+	}
+
+	for _, book := range books {
+		if id, ok := book.ID(); ok {
+			c.bookIndex[id] = book
+		}
+	}
+	for _, character := range characters {
+		if id, ok := character.ID(); ok {
+			c.characterIndex[id] = character
+		}
+	}
+	for _, house := range houses {
+		if id, ok := house.ID(); ok {
+			c.houseIndex[id] = house
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(c.bookIndex)) {
+		c.books = append(c.books, c.bookIndex[id])
+	}
+	for _, id := range slices.Sorted(maps.Keys(c.characterIndex)) {
+		c.characters = append(c.characters, c.characterIndex[id])
+	}
+	for _, id := range slices.Sorted(maps.Keys(c.houseIndex)) {
+		c.houses = append(c.houses, c.houseIndex[id])
+	}
+
+	return c
+}
+
+func (c *offlineClient) Books(request BookRequest) (BookResponse, error) {
+	return c.BooksContext(context.Background(), request)
+}
+
+func (c *offlineClient) BooksContext(ctx context.Context, request BookRequest) (BookResponse, error) {
+	if request == nil {
+		request = NewBookRequest()
+	}
+	params, err := offlineParams(ctx, request)
+	if err != nil {
+		return BookResponse{}, err
+	}
+
+	from, err := timeParam(params, "fromReleaseDate")
+	if err != nil {
+		return BookResponse{}, err
+	}
+	to, err := timeParam(params, "toReleaseDate")
+	if err != nil {
+		return BookResponse{}, err
+	}
+
+	matches := []Book{}
+	for _, book := range c.books {
+		if !matchString(params, "name", book.Name) {
+			continue
+		}
+		if from != nil && book.Released.Before(*from) {
+			continue
+		}
+		if to != nil && book.Released.After(*to) {
+			continue
+		}
+		matches = append(matches, book)
+	}
+
+	start, end, links := paginate(c.booksEndpoint, params, len(matches))
+	return BookResponse{Data: matches[start:end], links: links}, nil
+}
+
+func (c *offlineClient) Book(id int) (Book, error) {
+	return c.BookContext(context.Background(), id)
+}
+
+func (c *offlineClient) BookContext(ctx context.Context, id int) (Book, error) {
+	if err := ctx.Err(); err != nil {
+		return Book{}, err
+	}
+
+	book, ok := c.bookIndex[BookID(id)]
+	if !ok {
+		return Book{}, notFoundError(c.booksEndpoint, id)
+	}
+
+	return book, nil
+}
+
+func (c *offlineClient) Characters(request CharacterRequest) (CharacterResponse, error) {
+	return c.CharactersContext(context.Background(), request)
+}
+
+func (c *offlineClient) CharactersContext(ctx context.Context, request CharacterRequest) (CharacterResponse, error) {
+	if request == nil {
+		request = NewCharacterRequest()
+	}
+	params, err := offlineParams(ctx, request)
+	if err != nil {
+		return CharacterResponse{}, err
+	}
+
+	isAlive, err := boolParam(params, "isAlive")
+	if err != nil {
+		return CharacterResponse{}, err
+	}
+
+	matches := []Character{}
+	for _, character := range c.characters {
+		if !matchString(params, "name", character.Name) ||
+			!matchString(params, "gender", character.Gender) ||
+			!matchString(params, "culture", character.Culture) ||
+			!matchString(params, "born", character.Born) ||
+			!matchString(params, "died", character.Died) {
+			continue
+		}
+		if isAlive != nil && *isAlive != (character.Died == "") {
+			continue
+		}
+		matches = append(matches, character)
+	}
+
+	start, end, links := paginate(c.charactersEndpoint, params, len(matches))
+	return CharacterResponse{Data: matches[start:end], links: links}, nil
+}
+
+func (c *offlineClient) Character(id int) (Character, error) {
+	return c.CharacterContext(context.Background(), id)
+}
+
+func (c *offlineClient) CharacterContext(ctx context.Context, id int) (Character, error) {
+	if err := ctx.Err(); err != nil {
+		return Character{}, err
+	}
+
+	character, ok := c.characterIndex[CharacterID(id)]
+	if !ok {
+		return Character{}, notFoundError(c.charactersEndpoint, id)
+	}
+
+	return character, nil
+}
+
+func (c *offlineClient) Houses(request HouseRequest) (HouseResponse, error) {
+	return c.HousesContext(context.Background(), request)
+}
+
+func (c *offlineClient) HousesContext(ctx context.Context, request HouseRequest) (HouseResponse, error) {
+	if request == nil {
+		request = NewHouseRequest()
+	}
+	params, err := offlineParams(ctx, request)
+	if err != nil {
+		return HouseResponse{}, err
+	}
+
+	flags := map[string]*bool{}
+	for _, name := range []string{"hasWords", "hasTitles", "hasSeats", "hasDiedOut", "hasAncestralWeapons"} {
+		if flags[name], err = boolParam(params, name); err != nil {
+			return HouseResponse{}, err
+		}
+	}
+
+	matches := []House{}
+	for _, house := range c.houses {
+		if !matchString(params, "name", house.Name) ||
+			!matchString(params, "region", house.Region) ||
+			!matchString(params, "words", house.Words) {
+			continue
+		}
+		if !matchFlag(flags["hasWords"], house.Words != "") ||
+			!matchFlag(flags["hasTitles"], hasValues(house.Titles)) ||
+			!matchFlag(flags["hasSeats"], hasValues(house.Seats)) ||
+			!matchFlag(flags["hasDiedOut"], house.DiedOut != "") ||
+			!matchFlag(flags["hasAncestralWeapons"], hasValues(house.AncestralWeapons)) {
+			continue
+		}
+		matches = append(matches, house)
+	}
+
+	start, end, links := paginate(c.housesEndpoint, params, len(matches))
+	return HouseResponse{Data: matches[start:end], links: links}, nil
+}
+
+func (c *offlineClient) House(id int) (House, error) {
+	return c.HouseContext(context.Background(), id)
+}
+
+func (c *offlineClient) HouseContext(ctx context.Context, id int) (House, error) {
+	if err := ctx.Err(); err != nil {
+		return House{}, err
+	}
+
+	house, ok := c.houseIndex[HouseID(id)]
+	if !ok {
+		return House{}, notFoundError(c.housesEndpoint, id)
+	}
+
+	return house, nil
+}
+
+// notFoundError returns the error the client returns when the api has no
+// resource with the id, so both clients can be checked the same way.
+func notFoundError(endpoint string, id int) error {
+	return &APIError{
+		StatusCode: http.StatusNotFound,
+		Method:     http.MethodGet,
+		URL:        fmt.Sprintf("%s/%d", endpoint, id),
+		Header:     http.Header{},
+	}
+}
+
+// offlineParams validates the request and returns its parameters.
+func offlineParams(ctx context.Context, converter ParamConverter) (url.Values, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if v, ok := converter.(validator); ok {
+		if err := v.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return converter.Convert(), nil
+}
+
+func matchString(params url.Values, name, value string) bool {
+	param, ok := params[name]
+	return !ok || len(param) == 0 || param[0] == value
+}
+
+func matchFlag(flag *bool, value bool) bool {
+	return flag == nil || *flag == value
+}
+
+// hasValues reports whether the slice contains a non-empty value. The api
+// returns an empty string in place of an empty list for some fields.
+func hasValues(values []string) bool {
+	for _, value := range values {
+		if value != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func boolParam(params url.Values, name string) (*bool, error) {
+	value := params.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+func timeParam(params url.Values, name string) (*time.Time, error) {
+	value := params.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// paginate returns the bounds of the requested page within total results,
+// and the pagination links the api would return for it.
+func paginate(endpoint string, params url.Values, total int) (int, int, Links) {
+	page, err := strconv.Atoi(params.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(params.Get("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+
+	lastPage := (total + pageSize - 1) / pageSize
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	pageURL := func(p int) string {
+		query := url.Values{}
+		for key, values := range params {
+			query[key] = values
+		}
+		query.Set("page", strconv.Itoa(p))
+		query.Set("pageSize", strconv.Itoa(pageSize))
+
+		return fmt.Sprintf("%s?%s", endpoint, query.Encode())
+	}
+
+	link := func(rel string, p int) Link {
+		return Link{
+			URL:    pageURL(p),
+			Rel:    []string{rel},
+			Params: map[string]string{"rel": rel},
+		}
+	}
+
+	links := Links{link("first", 1)}
+	if page > 1 {
+		links = append(links, link("prev", page-1))
+	}
+	if page < lastPage {
+		links = append(links, link("next", page+1))
+	}
+	links = append(links, link("last", lastPage))
+
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	return start, end, links
+}
@@ -0,0 +1,281 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/goiaftest"
+)
+
+// filterFixtures returns resources whose fields differ, for the filters.
+func filterFixtures() goiaftest.Fixtures {
+	fixtures := goiaftest.Fixtures{}
+
+	released := func(year int) time.Time { return time.Date(year, 8, 1, 0, 0, 0, 0, time.UTC) }
+	for i, b := range []struct {
+		name     string
+		released time.Time
+	}{
+		{"A Game of Thrones", released(1996)},
+		{"A Clash of Kings", released(1998)},
+		{"A Storm of Swords", released(2000)},
+		{"A Feast for Crows", released(2005)},
+		{"A Dance with Dragons", released(2011)},
+	} {
+		fixtures.Books = append(fixtures.Books, goiaf.Book{
+			URL:      fmt.Sprintf("%s/books/%d", fixtureBaseURL, i+1),
+			Name:     b.name,
+			Released: b.released,
+		})
+	}
+
+	for i, c := range []struct {
+		name, gender, culture, born, died string
+	}{
+		{"Eddard Stark", "Male", "Northmen", "In 263 AC", "In 299 AC"},
+		{"Catelyn Stark", "Female", "Rivermen", "In 264 AC", "In 299 AC"},
+		{"Robb Stark", "Male", "Northmen", "In 283 AC", "In 299 AC"},
+		{"Sansa Stark", "Female", "Northmen", "In 286 AC", ""},
+		{"Arya Stark", "Female", "Northmen", "In 289 AC", ""},
+		{"Bran Stark", "Male", "Northmen", "In 290 AC", ""},
+		{"Hodor", "Male", "", "", ""},
+	} {
+		fixtures.Characters = append(fixtures.Characters, goiaf.Character{
+			URL:     fmt.Sprintf("%s/characters/%d", fixtureBaseURL, i+1),
+			Name:    c.name,
+			Gender:  c.gender,
+			Culture: c.culture,
+			Born:    c.born,
+			Died:    c.died,
+		})
+	}
+
+	fixtures.Houses = []goiaf.House{
+		{Name: "House Stark", Region: "The North", Words: "Winter is Coming", Titles: []string{"King in the North"}, Seats: []string{"Winterfell"}, AncestralWeapons: []string{"Ice"}},
+		{Name: "House Bolton", Region: "The North", Words: "Our Blades Are Sharp", Titles: []string{""}, Seats: []string{"The Dreadfort"}, AncestralWeapons: []string{""}},
+		{Name: "House Reyne", Region: "The Westerlands", Titles: []string{}, Seats: []string{""}, DiedOut: "262 AC"},
+		{Name: "House Tully", Region: "The Riverlands", Words: "Family, Duty, Honor", Titles: []string{"Lord of Riverrun"}, Seats: []string{"Riverrun"}},
+	}
+	for i := range fixtures.Houses {
+		fixtures.Houses[i].URL = fmt.Sprintf("%s/houses/%d", fixtureBaseURL, i+1)
+	}
+
+	return fixtures
+}
+
+// page is what a test compares between the offline client and the api: the
+// names of the result set and the parameters of its pagination links.
+type page struct {
+	names []string
+	links map[string]url.Values
+}
+
+func newPage(names []string, links map[string]func() (goiaf.ParamConverter, error)) page {
+	p := page{names: names, links: map[string]url.Values{}}
+	for rel, link := range links {
+		if request, err := link(); err == nil {
+			p.links[rel] = request.Convert()
+		}
+	}
+
+	return p
+}
+
+func bookPage(t *testing.T, client goiaf.Client, request goiaf.BookRequest) page {
+	t.Helper()
+
+	response, err := client.BooksContext(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, book := range response.Data {
+		names = append(names, book.Name)
+	}
+
+	return newPage(names, map[string]func() (goiaf.ParamConverter, error){
+		"next":  func() (goiaf.ParamConverter, error) { return response.Next() },
+		"prev":  func() (goiaf.ParamConverter, error) { return response.Prev() },
+		"first": func() (goiaf.ParamConverter, error) { return response.First() },
+		"last":  func() (goiaf.ParamConverter, error) { return response.Last() },
+	})
+}
+
+func characterPage(t *testing.T, client goiaf.Client, request goiaf.CharacterRequest) page {
+	t.Helper()
+
+	response, err := client.CharactersContext(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, character := range response.Data {
+		names = append(names, character.Name)
+	}
+
+	return newPage(names, map[string]func() (goiaf.ParamConverter, error){
+		"next":  func() (goiaf.ParamConverter, error) { return response.Next() },
+		"prev":  func() (goiaf.ParamConverter, error) { return response.Prev() },
+		"first": func() (goiaf.ParamConverter, error) { return response.First() },
+		"last":  func() (goiaf.ParamConverter, error) { return response.Last() },
+	})
+}
+
+func housePage(t *testing.T, client goiaf.Client, request goiaf.HouseRequest) page {
+	t.Helper()
+
+	response, err := client.HousesContext(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, house := range response.Data {
+		names = append(names, house.Name)
+	}
+
+	return newPage(names, map[string]func() (goiaf.ParamConverter, error){
+		"next":  func() (goiaf.ParamConverter, error) { return response.Next() },
+		"prev":  func() (goiaf.ParamConverter, error) { return response.Prev() },
+		"first": func() (goiaf.ParamConverter, error) { return response.First() },
+		"last":  func() (goiaf.ParamConverter, error) { return response.Last() },
+	})
+}
+
+// assertSamePage checks that the offline client and the api return the
+// expected names and the same pagination links for a query.
+func assertSamePage(t *testing.T, name string, offline, api page, want []string) {
+	t.Helper()
+
+	if !reflect.DeepEqual(offline.names, want) {
+		t.Errorf("%s: offline client returned %q, want %q", name, offline.names, want)
+	}
+	if !reflect.DeepEqual(api.names, offline.names) {
+		t.Errorf("%s: api returned %q, offline client %q", name, api.names, offline.names)
+	}
+	if !reflect.DeepEqual(api.links, offline.links) {
+		t.Errorf("%s: api links are %v, offline client links %v", name, api.links, offline.links)
+	}
+}
+
+func newFilterClients(t *testing.T) (goiaf.Client, goiaf.Client) {
+	t.Helper()
+
+	fixtures := filterFixtures()
+	server := goiaftest.NewServer(fixtures)
+	t.Cleanup(server.Close)
+
+	return goiaf.NewOfflineClient(fixtures.Books, fixtures.Characters, fixtures.Houses), server.Client()
+}
+
+func TestOfflineClientBookFilters(t *testing.T) {
+	offline, api := newFilterClients(t)
+	year := func(year int) time.Time { return time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string
+		request goiaf.BookRequest
+		want    []string
+	}{
+		{"all", goiaf.NewBookRequest(), []string{"A Game of Thrones", "A Clash of Kings", "A Storm of Swords", "A Feast for Crows", "A Dance with Dragons"}},
+		{"name", goiaf.NewBookRequest().Name("A Clash of Kings"), []string{"A Clash of Kings"}},
+		{"from", goiaf.NewBookRequest().FromReleaseDate(year(2000)), []string{"A Storm of Swords", "A Feast for Crows", "A Dance with Dragons"}},
+		{"to", goiaf.NewBookRequest().ToReleaseDate(year(2000)), []string{"A Game of Thrones", "A Clash of Kings"}},
+		{"range", goiaf.NewBookRequest().FromReleaseDate(year(1997)).ToReleaseDate(year(2006)), []string{"A Clash of Kings", "A Storm of Swords", "A Feast for Crows"}},
+		{"empty range", goiaf.NewBookRequest().FromReleaseDate(year(2001)).ToReleaseDate(year(2004)), []string{}},
+		{"range page 2", goiaf.NewBookRequest().FromReleaseDate(year(1997)).PageSize(2).Page(2), []string{"A Feast for Crows", "A Dance with Dragons"}},
+	}
+
+	for _, test := range tests {
+		assertSamePage(t, test.name, bookPage(t, offline, test.request), bookPage(t, api, test.request), test.want)
+	}
+}
+
+func TestOfflineClientCharacterFilters(t *testing.T) {
+	offline, api := newFilterClients(t)
+
+	tests := []struct {
+		name    string
+		request goiaf.CharacterRequest
+		want    []string
+	}{
+		{"gender", goiaf.NewCharacterRequest().Gender("Female"), []string{"Catelyn Stark", "Sansa Stark", "Arya Stark"}},
+		{"culture", goiaf.NewCharacterRequest().Culture("Rivermen"), []string{"Catelyn Stark"}},
+		{"born", goiaf.NewCharacterRequest().Born("In 289 AC"), []string{"Arya Stark"}},
+		{"died", goiaf.NewCharacterRequest().Died("In 299 AC"), []string{"Eddard Stark", "Catelyn Stark", "Robb Stark"}},
+		{"alive", goiaf.NewCharacterRequest().IsAlive(true), []string{"Sansa Stark", "Arya Stark", "Bran Stark", "Hodor"}},
+		{"dead", goiaf.NewCharacterRequest().IsAlive(false), []string{"Eddard Stark", "Catelyn Stark", "Robb Stark"}},
+		{"combined", goiaf.NewCharacterRequest().Gender("Male").Culture("Northmen").IsAlive(true), []string{"Bran Stark"}},
+		{"no match", goiaf.NewCharacterRequest().Name("Jon Snow"), []string{}},
+		{"first page", goiaf.NewCharacterRequest().Culture("Northmen").PageSize(2), []string{"Eddard Stark", "Robb Stark"}},
+		{"middle page", goiaf.NewCharacterRequest().Culture("Northmen").PageSize(2).Page(2), []string{"Sansa Stark", "Arya Stark"}},
+		{"last page", goiaf.NewCharacterRequest().Culture("Northmen").PageSize(2).Page(3), []string{"Bran Stark"}},
+		{"past the last page", goiaf.NewCharacterRequest().PageSize(5).Page(4), []string{}},
+	}
+
+	for _, test := range tests {
+		assertSamePage(t, test.name, characterPage(t, offline, test.request), characterPage(t, api, test.request), test.want)
+	}
+}
+
+func TestOfflineClientHouseFilters(t *testing.T) {
+	offline, api := newFilterClients(t)
+
+	tests := []struct {
+		name    string
+		request goiaf.HouseRequest
+		want    []string
+	}{
+		{"region", goiaf.NewHouseRequest().Region("The North"), []string{"House Stark", "House Bolton"}},
+		{"words", goiaf.NewHouseRequest().Words("Winter is Coming"), []string{"House Stark"}},
+		{"has words", goiaf.NewHouseRequest().HasWords(true), []string{"House Stark", "House Bolton", "House Tully"}},
+		{"has no words", goiaf.NewHouseRequest().HasWords(false), []string{"House Reyne"}},
+		{"has titles", goiaf.NewHouseRequest().HasTitles(true), []string{"House Stark", "House Tully"}},
+		{"has no titles", goiaf.NewHouseRequest().HasTitles(false), []string{"House Bolton", "House Reyne"}},
+		{"has seats", goiaf.NewHouseRequest().HasSeats(true), []string{"House Stark", "House Bolton", "House Tully"}},
+		{"has died out", goiaf.NewHouseRequest().HasDiedOut(true), []string{"House Reyne"}},
+		{"has ancestral weapons", goiaf.NewHouseRequest().HasAncestralWeapons(true), []string{"House Stark"}},
+		{"has no ancestral weapons", goiaf.NewHouseRequest().HasAncestralWeapons(false), []string{"House Bolton", "House Reyne", "House Tully"}},
+		{"combined", goiaf.NewHouseRequest().Region("The North").HasTitles(false), []string{"House Bolton"}},
+		{"paged", goiaf.NewHouseRequest().HasWords(true).PageSize(1).Page(2), []string{"House Bolton"}},
+	}
+
+	for _, test := range tests {
+		assertSamePage(t, test.name, housePage(t, offline, test.request), housePage(t, api, test.request), test.want)
+	}
+}
+
+func TestOfflineClientPaginationLinks(t *testing.T) {
+	offline, _ := newFilterClients(t)
+
+	p := characterPage(t, offline, goiaf.NewCharacterRequest().Culture("Northmen").PageSize(2).Page(2))
+
+	want := map[string]string{"next": "3", "prev": "1", "first": "1", "last": "3"}
+	if len(p.links) != len(want) {
+		t.Errorf("links = %v, want next, prev, first and last", p.links)
+	}
+	for rel, page := range want {
+		if got := p.links[rel]; got.Get("page") != page || got.Get("pageSize") != "2" || got.Get("culture") != "Northmen" {
+			t.Errorf("%s link = %v, want page %s of Northmen with page size 2", rel, got, page)
+		}
+	}
+
+	p = characterPage(t, offline, goiaf.NewCharacterRequest().PageSize(10))
+	if _, ok := p.links["next"]; ok {
+		t.Errorf("single page has a next link: %v", p.links)
+	}
+	if _, ok := p.links["prev"]; ok {
+		t.Errorf("first page has a prev link: %v", p.links)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures the client returned by NewClient.
+type Option func(*client)
+
+// WithBaseURL sets the base URL of the api, e.g. to use a self-hosted
+// mirror or a local test server. The default is
+// http://www.anapioficeandfire.com/api.
+func WithBaseURL(baseURL string) Option {
+	return func(c *client) {
+		c.setBaseURL(baseURL)
+	}
+}
+
+// WithHTTPClient sets the http.Client which is used to perform the requests.
+// The given client is copied, so options applied afterwards such as
+// WithTimeout and WithTransport will not modify it.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		if httpClient == nil {
+			return
+		}
+		hc := *httpClient
+		c.httpClient = &hc
+	}
+}
+
+// WithTimeout sets the timeout for every request made by the client.
+// The default timeout is 15 seconds. A zero value means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithUserAgent sets the value of the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTransport sets the http.RoundTripper which is used by the
+// underlying http.Client to perform the requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *client) {
+		c.httpClient.Transport = transport
+	}
+}
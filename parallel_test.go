@@ -0,0 +1,110 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	const n = 50
+
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+	)
+	results := make([]int, n)
+
+	err := parallel(context.Background(), n, 3, func(ctx context.Context, i int) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		// Later indexes finish first, the results still keep their order.
+		time.Sleep(time.Duration(n-i) * 10 * time.Microsecond)
+		results[i] = i * i
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, result := range results {
+		if result != i*i {
+			t.Fatalf("results[%d] = %d, want %d", i, result, i*i)
+		}
+	}
+	if peak > 3 {
+		t.Errorf("%d calls ran concurrently, want at most 3", peak)
+	}
+}
+
+func TestParallelStopsAtFirstError(t *testing.T) {
+	const n = 1000
+	errFailed := errors.New("failed")
+
+	var calls int32
+	cancelled := false
+	err := parallel(context.Background(), n, 2, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&calls, 1)
+		switch i {
+		case 0:
+			// The call still running must see its context cancelled.
+			select {
+			case <-ctx.Done():
+				cancelled = true
+			case <-time.After(time.Second):
+			}
+		case 1:
+			return errFailed
+		}
+		return nil
+	})
+
+	if err != errFailed {
+		t.Errorf("parallel returned %v, want the first error", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls >= n {
+		t.Errorf("parallel made all %d calls, want it to stop after the error", calls)
+	}
+	if !cancelled {
+		t.Error("the context of the running calls was not cancelled")
+	}
+}
+
+func TestParallelCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := parallel(ctx, 10, 2, func(ctx context.Context, i int) error {
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("parallel returned %v, want context.Canceled", err)
+	}
+}
+
+func TestParallelNoWork(t *testing.T) {
+	err := parallel(context.Background(), 0, 0, func(ctx context.Context, i int) error {
+		t.Error("fn called without work")
+		return nil
+	})
+	if err != nil {
+		t.Errorf("parallel returned %v, want nil", err)
+	}
+}
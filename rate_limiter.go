@@ -0,0 +1,101 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket which limits the rate of requests sent to the api.
+//
+// The bucket holds at most burst tokens and is refilled with the given number
+// of tokens per second. Every request takes one token, waiting for it if the
+// bucket is empty. A RateLimiter is safe for concurrent use and can be shared
+// between several clients.
+type RateLimiter struct {
+	mu sync.Mutex
+
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter which allows requestsPerSecond requests
+// per second on average, with bursts of up to burst requests. A burst of less
+// than one is treated as one. A rate of zero or less disables the limit, so
+// Wait only fails if its context is done.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond requests per second,
+// with bursts of up to burst requests. A rate of zero or less disables the
+// limit, see NewRateLimiter.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return WithRateLimiter(NewRateLimiter(requestsPerSecond, burst))
+}
+
+// WithRateLimiter sets the rate limiter of the client. Every request sent by
+// the client, including retries, waits for the limiter first.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// Wait blocks until a request is allowed to be sent, or until the context is
+// done, in which case the context error is returned.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	l.refill()
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if err := sleep(ctx, wait); err != nil {
+		// The request is not sent, so give back the reserved token.
+		l.mu.Lock()
+		l.refill()
+		l.tokens++
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// refill adds the tokens gained since the last refill. It must be called
+// with the mutex held.
+func (l *RateLimiter) refill() {
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
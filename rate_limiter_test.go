@@ -0,0 +1,91 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitFor returns the time a Wait of the limiter takes.
+func waitFor(t *testing.T, l *RateLimiter) time.Duration {
+	t.Helper()
+
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return time.Since(start)
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := NewRateLimiter(10, 3)
+
+	for i := 0; i < 3; i++ {
+		if wait := waitFor(t, l); wait > 20*time.Millisecond {
+			t.Errorf("Wait %d took %v, want no wait within the burst", i+1, wait)
+		}
+	}
+	if wait := waitFor(t, l); wait < 70*time.Millisecond {
+		t.Errorf("Wait after the burst took %v, want about 100ms", wait)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l := NewRateLimiter(20, 2)
+	waitFor(t, l)
+	waitFor(t, l)
+
+	// Two tokens are refilled after 100ms, but not more than the burst.
+	time.Sleep(250 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if wait := waitFor(t, l); wait > 20*time.Millisecond {
+			t.Errorf("Wait %d after refill took %v, want no wait", i+1, wait)
+		}
+	}
+	if wait := waitFor(t, l); wait < 30*time.Millisecond {
+		t.Errorf("Wait beyond the burst took %v, want about 50ms", wait)
+	}
+}
+
+func TestRateLimiterCancelReturnsToken(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	waitFor(t, l)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait returned %v, want context.DeadlineExceeded", err)
+	}
+
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+
+	// Without giving the token back the bucket would be at -1.
+	if tokens < -0.1 || tokens > 0.1 {
+		t.Errorf("bucket holds %.2f tokens after a cancelled Wait, want about 0", tokens)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	l := NewRateLimiter(0, 1)
+	for i := 0; i < 100; i++ {
+		if wait := waitFor(t, l); wait > 20*time.Millisecond {
+			t.Fatalf("Wait took %v with the limit disabled", wait)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait with a cancelled context returned %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		request ParamConverter
+		err     error
+	}{
+		{"default", NewBookRequest(), nil},
+		{"page 0", NewBookRequest().Page(0), ErrInvalidPage},
+		{"negative page", NewCharacterRequest().Page(-1), ErrInvalidPage},
+		{"page corrected", NewBookRequest().Page(0).Page(2), nil},
+		{"page size 0", NewCharacterRequest().PageSize(0), ErrInvalidPageSize},
+		{"page size 51", NewHouseRequest().PageSize(MaxPageSize + 1), ErrInvalidPageSize},
+		{"page size 50", NewHouseRequest().PageSize(MaxPageSize), nil},
+		{"page size corrected", NewHouseRequest().PageSize(100).PageSize(20), nil},
+		{"limit 51", NewCharacterRequest().Limit(51), ErrInvalidPageSize},
+		{"page corrected after page size", NewBookRequest().Page(0).PageSize(0).Page(1), ErrInvalidPageSize},
+	}
+
+	client := NewOfflineClient(nil, nil, nil)
+	for _, test := range tests {
+		if err := test.request.(validator).validate(); err != test.err {
+			t.Errorf("%s: validate() = %v, want %v", test.name, err, test.err)
+		}
+
+		var err error
+		switch request := test.request.(type) {
+		case BookRequest:
+			_, err = client.BooksContext(context.Background(), request)
+		case CharacterRequest:
+			_, err = client.CharactersContext(context.Background(), request)
+		case HouseRequest:
+			_, err = client.HousesContext(context.Background(), request)
+		}
+		if err != test.err {
+			t.Errorf("%s: sending the request returned %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+const fixtureURL = "http://www.anapioficeandfire.com/api"
+
+// paginationLinks returns the next, prev, first and last links the api
+// sends for the given page of a result set with last pages.
+func paginationLinks(t *testing.T, endpoint string, page, last int, request func(page int) ParamConverter) Links {
+	t.Helper()
+
+	link := func(page int, rel string) string {
+		return fmt.Sprintf(`<%s/%s?%s>; rel="%s"`, fixtureURL, endpoint, request(page).Convert().Encode(), rel)
+	}
+
+	links, err := ParseLinks(strings.Join([]string{
+		link(page+1, "next"),
+		link(page-1, "prev"),
+		link(1, "first"),
+		link(last, "last"),
+	}, ", "), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return links
+}
+
+func TestPaginationRoundTrip(t *testing.T) {
+	books := func(page int) ParamConverter {
+		return NewBookRequest().Page(page).PageSize(5).Name("A Game of Thrones").
+			FromReleaseDate(time.Date(1996, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	characters := func(page int) ParamConverter {
+		return NewCharacterRequest().Page(page).PageSize(20).Gender("Female").Culture("Northmen").IsAlive(true)
+	}
+	houses := func(page int) ParamConverter {
+		return NewHouseRequest().Page(page).PageSize(MaxPageSize).Region("The North").HasWords(true).HasDiedOut(false)
+	}
+
+	bookResponse := BookResponse{links: paginationLinks(t, "books", 3, 7, books)}
+	characterResponse := CharacterResponse{links: paginationLinks(t, "characters", 3, 7, characters)}
+	houseResponse := HouseResponse{links: paginationLinks(t, "houses", 3, 7, houses)}
+
+	tests := []struct {
+		name    string
+		request func(page int) ParamConverter
+		methods []func() (ParamConverter, error)
+	}{
+		{"books", books, []func() (ParamConverter, error){
+			func() (ParamConverter, error) { return bookResponse.Next() },
+			func() (ParamConverter, error) { return bookResponse.Prev() },
+			func() (ParamConverter, error) { return bookResponse.First() },
+			func() (ParamConverter, error) { return bookResponse.Last() },
+		}},
+		{"characters", characters, []func() (ParamConverter, error){
+			func() (ParamConverter, error) { return characterResponse.Next() },
+			func() (ParamConverter, error) { return characterResponse.Prev() },
+			func() (ParamConverter, error) { return characterResponse.First() },
+			func() (ParamConverter, error) { return characterResponse.Last() },
+		}},
+		{"houses", houses, []func() (ParamConverter, error){
+			func() (ParamConverter, error) { return houseResponse.Next() },
+			func() (ParamConverter, error) { return houseResponse.Prev() },
+			func() (ParamConverter, error) { return houseResponse.First() },
+			func() (ParamConverter, error) { return houseResponse.Last() },
+		}},
+	}
+
+	for _, test := range tests {
+		for i, page := range []int{4, 2, 1, 7} {
+			rel := []string{"next", "prev", "first", "last"}[i]
+
+			request, err := test.methods[i]()
+			if err != nil {
+				t.Errorf("%s: %s returned %v", test.name, rel, err)
+				continue
+			}
+
+			if got, want := request.Convert(), test.request(page).Convert(); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %s request = %v, want %v", test.name, rel, got, want)
+			}
+			if err := request.(validator).validate(); err != nil {
+				t.Errorf("%s: %s request is invalid: %v", test.name, rel, err)
+			}
+		}
+	}
+}
+
+func TestPaginationMissingLinks(t *testing.T) {
+	if _, err := (BookResponse{}).Next(); err != ErrNoResultSet {
+		t.Errorf("Next() without links returned %v, want ErrNoResultSet", err)
+	}
+
+	links, err := ParseLinks(`<`+fixtureURL+`/houses?page=2>; rel="next", <`+fixtureURL+`/houses?page=0&pageSize=10>; rel="prev"`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := HouseResponse{links: links}
+
+	if _, err := response.Next(); err != ErrPaginationInfoMissing {
+		t.Errorf("Next() without a page size returned %v, want ErrPaginationInfoMissing", err)
+	}
+
+	request, err := response.Prev()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := request.(validator).validate(); err != ErrInvalidPage {
+		t.Errorf("request for page 0 from the api validates to %v, want ErrInvalidPage", err)
+	}
+}
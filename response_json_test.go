@@ -0,0 +1,93 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+func TestCharacterResponseJSONRoundTrip(t *testing.T) {
+	server := newTestServer(t)
+	client := server.Client()
+
+	response, err := client.CharactersContext(context.Background(), goiaf.NewCharacterRequest().Gender("Female").PageSize(5).Page(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := goiaf.CharacterResponse{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded.Data, response.Data) {
+		t.Errorf("decoded data = %v, want %v", decoded.Data, response.Data)
+	}
+
+	next, err := decoded.Next()
+	if err != nil {
+		t.Fatalf("Next() on the decoded response returned %v", err)
+	}
+	want, err := response.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(next.Convert(), want.Convert()) {
+		t.Errorf("decoded Next() = %v, want %v", next.Convert(), want.Convert())
+	}
+
+	page, err := client.CharactersContext(context.Background(), next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 5 || page.Data[0].Name != "Character 11" {
+		t.Errorf("next page of the decoded response starts with %v, want Character 11", page.Data)
+	}
+	if _, err := decoded.Prev(); err != nil {
+		t.Errorf("Prev() on the decoded response returned %v", err)
+	}
+}
+
+func TestBookResponseJSONKeepsReleaseZone(t *testing.T) {
+	released := time.Date(1996, 8, 1, 0, 0, 0, 0, time.FixedZone("BST", 60*60))
+	client := goiaf.NewOfflineClient([]goiaf.Book{{URL: fixtureBaseURL + "/books/1", Name: "A Game of Thrones", Released: released}}, nil, nil)
+
+	response, err := client.BooksContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := goiaf.BookResponse{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decoded.Data) != 1 || !decoded.Data[0].Released.Equal(released) {
+		t.Fatalf("decoded books = %v, want one released at %v", decoded.Data, released)
+	}
+	if _, offset := decoded.Data[0].Released.Zone(); offset != 60*60 {
+		t.Errorf("decoded release date has offset %d, want %d", offset, 60*60)
+	}
+	if _, err := decoded.Next(); err != goiaf.ErrNoResultSet {
+		t.Errorf("Next() on the single page returned %v, want ErrNoResultSet", err)
+	}
+	if _, err := decoded.Last(); err != nil {
+		t.Errorf("Last() on the decoded response returned %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes when and how often a failed request is retried.
+//
+// Only GET requests are sent by the client, so every request is safe to retry.
+// The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for a request, including
+	// the first one. A value of one or less disables retries.
+	MaxAttempts int
+
+	// BaseBackoff is the time to wait before the first retry. The wait time
+	// is doubled for every following retry.
+	BaseBackoff time.Duration
+
+	// MaxBackoff is the upper bound of the wait time between two attempts.
+	// A zero value means no upper bound. If the api asks to wait longer with
+	// a Retry-After header, the request is not retried and fails with the
+	// APIError of the response.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction, between 0 and 1, by which the wait time is
+	// randomly reduced, to avoid many clients retrying at the same time.
+	Jitter float64
+
+	// RetryableStatuses are the HTTP status codes which are retried.
+	RetryableStatuses []int
+
+	// RetryNetworkErrors determines whether errors which occur before a
+	// response is received, such as connection resets, are retried.
+	RetryNetworkErrors bool
+}
+
+// DefaultRetryPolicy is a retry policy suitable for the public api. It retries
+// rate limited requests, server errors and network errors up to three times.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseBackoff: time.Millisecond * 500,
+	MaxBackoff:  time.Second * 30,
+	Jitter:      0.2,
+	RetryableStatuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+	RetryNetworkErrors: true,
+}
+
+// WithRetryPolicy sets the retry policy of the client. By default failed
+// requests are not retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *client) {
+		c.retryPolicy = policy
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrInteractionNotFound) {
+		return false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return p.RetryNetworkErrors
+	}
+
+	for _, status := range p.RetryableStatuses {
+		if apiErr.StatusCode == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns the time to wait after the given failed attempt, and
+// whether the request should be retried at all. A Retry-After header sent
+// by the api takes precedence over the exponential backoff, unless it
+// exceeds MaxBackoff.
+func (p RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if wait, ok := retryAfter(apiErr.Header.Get("Retry-After")); ok {
+			return wait, p.MaxBackoff <= 0 || wait <= p.MaxBackoff
+		}
+	}
+
+	wait := p.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if p.MaxBackoff > 0 && wait >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		wait -= time.Duration(rand.Float64() * p.Jitter * float64(wait))
+	}
+
+	return wait, true
+}
+
+// retryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := time.Until(date)
+	if wait < 0 {
+		wait = 0
+	}
+
+	return wait, true
+}
+
+// sleep waits for the given duration or until the context is done,
+// in which case the context error is returned.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
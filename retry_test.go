@@ -0,0 +1,91 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoffGrowth(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+
+	for i, w := range want {
+		wait, ok := policy.backoff(i+1, errors.New("connection reset"))
+		if !ok || wait != w*time.Millisecond {
+			t.Errorf("backoff(%d) = %v, %v, want %v, true", i+1, wait, ok, w*time.Millisecond)
+		}
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		wait, _ := policy.backoff(1, errors.New("connection reset"))
+		if wait < 500*time.Millisecond || wait > time.Second {
+			t.Fatalf("backoff(1) = %v, want between 500ms and 1s", wait)
+		}
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: time.Millisecond, MaxBackoff: 10 * time.Second}
+	rateLimited := func(retryAfter string) error {
+		return &APIError{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {retryAfter}}}
+	}
+
+	if wait, ok := policy.backoff(1, rateLimited("2")); !ok || wait != 2*time.Second {
+		t.Errorf("backoff with Retry-After 2 = %v, %v, want 2s, true", wait, ok)
+	}
+	if _, ok := policy.backoff(1, rateLimited("86400")); ok {
+		t.Error("backoff with Retry-After 86400 retries, want no retry above MaxBackoff")
+	}
+	if wait, ok := policy.backoff(1, rateLimited("soon")); !ok || wait != time.Millisecond {
+		t.Errorf("backoff with invalid Retry-After = %v, %v, want 1ms, true", wait, ok)
+	}
+
+	date := time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)
+	if wait, ok := policy.backoff(1, rateLimited(date)); !ok || wait <= 3*time.Second || wait > 5*time.Second {
+		t.Errorf("backoff with Retry-After %s = %v, %v, want about 5s", date, wait, ok)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	policy := DefaultRetryPolicy
+
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{&APIError{StatusCode: http.StatusInternalServerError}, true},
+		{&APIError{StatusCode: http.StatusBadGateway}, true},
+		{&APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{&APIError{StatusCode: http.StatusGatewayTimeout}, true},
+		{&APIError{StatusCode: http.StatusNotFound}, false},
+		{&APIError{StatusCode: http.StatusBadRequest}, false},
+		{errors.New("connection reset"), true},
+		{context.Canceled, false},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), false},
+		{fmt.Errorf("%w: GET /", ErrInteractionNotFound), false},
+	}
+
+	for _, test := range tests {
+		if got := policy.retryable(test.err); got != test.want {
+			t.Errorf("retryable(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+
+	policy.RetryNetworkErrors = false
+	if policy.retryable(errors.New("connection reset")) {
+		t.Error("retryable(network error) = true with RetryNetworkErrors disabled")
+	}
+}
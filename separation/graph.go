@@ -0,0 +1,297 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package separation finds how characters are connected, through family,
+houses and books.
+
+Characters, houses and books are the nodes of a Graph. Edges link parents
+and children, siblings and spouses, characters and the houses they are loyal
+or sworn to, and characters and the books they appear in:
+
+	s, err := snapshot.Load("westeros.tar")
+	checkErr(err)
+
+	g := separation.FromSnapshot(s)
+	path, err := g.ShortestPath(separation.CharacterNode(148), separation.CharacterNode(583))
+	checkErr(err)
+
+	fmt.Println(path, path.Len())
+*/
+package separation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/genealogy"
+	"github.com/mattiaspernhult/goiaf/snapshot"
+)
+
+// NodeKind is the kind of resource a Node stands for.
+type NodeKind int
+
+const (
+	// CharacterKind is used for nodes of characters.
+	CharacterKind NodeKind = iota
+
+	// HouseKind is used for nodes of houses.
+	HouseKind
+
+	// BookKind is used for nodes of books.
+	BookKind
+)
+
+// Node is a character, house or book of a Graph.
+type Node struct {
+	Kind NodeKind
+	ID   int
+}
+
+// CharacterNode returns the node of the character.
+func CharacterNode(id goiaf.CharacterID) Node {
+	return Node{Kind: CharacterKind, ID: int(id)}
+}
+
+// HouseNode returns the node of the house.
+func HouseNode(id goiaf.HouseID) Node {
+	return Node{Kind: HouseKind, ID: int(id)}
+}
+
+// BookNode returns the node of the book.
+func BookNode(id goiaf.BookID) Node {
+	return Node{Kind: BookKind, ID: int(id)}
+}
+
+// String makes the Node type implement the fmt.Stringer interface.
+func (n Node) String() string {
+	switch n.Kind {
+	case HouseKind:
+		return fmt.Sprintf("House %d", n.ID)
+	case BookKind:
+		return fmt.Sprintf("Book %d", n.ID)
+	}
+
+	return fmt.Sprintf("Character %d", n.ID)
+}
+
+// EdgeKind is the reason two nodes are connected.
+type EdgeKind int
+
+const (
+	// EdgeParent connects a character with its father and mother.
+	EdgeParent EdgeKind = iota
+
+	// EdgeSibling connects characters sharing a father or a mother.
+	EdgeSibling
+
+	// EdgeSpouse connects a character with its spouse.
+	EdgeSpouse
+
+	// EdgeAllegiance connects a character with the houses in its
+	// AllegianceIds.
+	EdgeAllegiance
+
+	// EdgeSwornMember connects a house with the characters in its
+	// SwornMembersIds.
+	EdgeSwornMember
+
+	// EdgeAppearance connects a book with the characters in its
+	// CharacterIds.
+	EdgeAppearance
+
+	// EdgePOV connects a book with the characters in its PovCharacterIds.
+	EdgePOV
+)
+
+// edgeKinds are all kinds of edges, in order.
+var edgeKinds = []EdgeKind{
+	EdgeParent, EdgeSibling, EdgeSpouse, EdgeAllegiance, EdgeSwornMember, EdgeAppearance, EdgePOV,
+}
+
+// String makes the EdgeKind type implement the fmt.Stringer interface.
+func (k EdgeKind) String() string {
+	switch k {
+	case EdgeParent:
+		return "parent"
+	case EdgeSibling:
+		return "sibling"
+	case EdgeSpouse:
+		return "spouse"
+	case EdgeAllegiance:
+		return "allegiance"
+	case EdgeSwornMember:
+		return "sworn member"
+	case EdgeAppearance:
+		return "appearance"
+	case EdgePOV:
+		return "pov"
+	}
+
+	return "unknown"
+}
+
+// Edge is a connection from one node to another. Every connection is
+// stored in both directions, each with its own label.
+type Edge struct {
+	From Node
+	To   Node
+	Kind EdgeKind
+
+	// The reason of the connection as seen from From, e.g. "child of",
+	// "sworn to" or "appears in".
+	Label string
+}
+
+// Graph is an undirected graph of characters, houses and books. Resources
+// which are referred to but were not loaded are part of the graph too, they
+// are named by their kind and id.
+type Graph struct {
+	names map[Node]string
+	edges map[Node][]Edge
+}
+
+// New builds a Graph from the books, characters and houses. Resources without
+// a valid id are left out.
+func New(books []goiaf.Book, characters []goiaf.Character, houses []goiaf.House) *Graph {
+	g := &Graph{
+		names: map[Node]string{},
+		edges: map[Node][]Edge{},
+	}
+
+	for _, book := range books {
+		if id, ok := book.ID(); ok {
+			g.names[BookNode(id)] = book.Name
+		}
+	}
+	for _, character := range characters {
+		if id, ok := character.ID(); ok {
+			g.names[CharacterNode(id)] = characterName(character)
+		}
+	}
+	for _, house := range houses {
+		if id, ok := house.ID(); ok {
+			g.names[HouseNode(id)] = house.Name
+		}
+	}
+
+	family := genealogy.NewFamilyGraph(characters)
+	for _, id := range family.Characters() {
+		character := CharacterNode(id)
+
+		for _, parent := range family.Parents(id) {
+			g.connect(character, CharacterNode(parent), EdgeParent, "child of", "parent of")
+		}
+		for _, sibling := range family.Siblings(id) {
+			if id < sibling {
+				g.connect(character, CharacterNode(sibling), EdgeSibling, "sibling", "sibling")
+			}
+		}
+		for _, sibling := range family.HalfSiblings(id) {
+			if id < sibling {
+				g.connect(character, CharacterNode(sibling), EdgeSibling, "half-sibling", "half-sibling")
+			}
+		}
+		for _, spouse := range family.Spouses(id) {
+			if id < spouse {
+				g.connect(character, CharacterNode(spouse), EdgeSpouse, "spouse", "spouse")
+			}
+		}
+	}
+
+	for _, character := range characters {
+		id, ok := character.ID()
+		if !ok {
+			continue
+		}
+		for _, house := range character.AllegianceIds {
+			g.connect(CharacterNode(id), HouseNode(house), EdgeAllegiance, "loyal to", "has loyal")
+		}
+	}
+	for _, house := range houses {
+		id, ok := house.ID()
+		if !ok {
+			continue
+		}
+		for _, member := range house.SwornMembersIds {
+			g.connect(CharacterNode(member), HouseNode(id), EdgeSwornMember, "sworn to", "has sworn")
+		}
+	}
+	for _, book := range books {
+		id, ok := book.ID()
+		if !ok {
+			continue
+		}
+		for _, character := range book.CharacterIds {
+			g.connect(CharacterNode(character), BookNode(id), EdgeAppearance, "appears in", "features")
+		}
+		for _, character := range book.PovCharacterIds {
+			g.connect(CharacterNode(character), BookNode(id), EdgePOV, "POV in", "has POV")
+		}
+	}
+
+	for node, edges := range g.edges {
+		sort.SliceStable(edges, func(i, j int) bool { return less(edges[i].To, edges[j].To) })
+		g.edges[node] = edges
+	}
+
+	return g
+}
+
+// FromSnapshot builds a Graph from the resources of the snapshot.
+func FromSnapshot(s *snapshot.Snapshot) *Graph {
+	return New(s.Books, s.Characters, s.Houses)
+}
+
+// Name returns the name of the node, or its kind and id if the resource was
+// not loaded or has no name.
+func (g *Graph) Name(n Node) string {
+	if name := g.names[n]; name != "" {
+		return name
+	}
+
+	return n.String()
+}
+
+// Edges returns the edges from the node.
+func (g *Graph) Edges(n Node) []Edge {
+	return append([]Edge{}, g.edges[n]...)
+}
+
+// connect adds an edge between the nodes in both directions.
+func (g *Graph) connect(a, b Node, kind EdgeKind, label, reverse string) {
+	g.edges[a] = append(g.edges[a], Edge{From: a, To: b, Kind: kind, Label: label})
+	g.edges[b] = append(g.edges[b], Edge{From: b, To: a, Kind: kind, Label: reverse})
+}
+
+func (g *Graph) has(n Node) bool {
+	_, named := g.names[n]
+	_, connected := g.edges[n]
+
+	return named || connected
+}
+
+// characterName returns the name of the character, or its first alias if
+// the name is unknown.
+func characterName(character goiaf.Character) string {
+	if character.Name != "" {
+		return character.Name
+	}
+	for _, alias := range character.Aliases {
+		if alias != "" {
+			return alias
+		}
+	}
+
+	return ""
+}
+
+func less(a, b Node) bool {
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+
+	return a.ID < b.ID
+}
@@ -0,0 +1,219 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package separation
+
+import (
+	"container/heap"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrUnknownNode will be used if a path is searched from or to a node
+	// which is not part of the graph.
+	ErrUnknownNode = errors.New("Node is not part of the graph")
+
+	// ErrNoPath will be used if the nodes are not connected.
+	ErrNoPath = errors.New("No path between the nodes")
+
+	// ErrNegativeWeight will be used if an edge kind is given a negative weight.
+	ErrNegativeWeight = errors.New("Edge weights must not be negative")
+)
+
+// Option is used to configure a path search.
+type Option func(*options)
+
+type options struct {
+	weights map[EdgeKind]float64
+	exclude map[EdgeKind]bool
+}
+
+// WithWeight sets the cost of following an edge of the kind. All kinds have
+// a weight of 1 by default, so the shortest path has the fewest edges.
+func WithWeight(kind EdgeKind, weight float64) Option {
+	return func(o *options) {
+		o.weights[kind] = weight
+	}
+}
+
+// Without excludes edges of the kinds from the search.
+func Without(kinds ...EdgeKind) Option {
+	return func(o *options) {
+		for _, kind := range kinds {
+			o.exclude[kind] = true
+		}
+	}
+}
+
+// Path is a chain of edges from one node to another.
+type Path struct {
+	// The nodes of the path, from the first to the last.
+	Nodes []Node
+
+	// The names of the nodes.
+	Names []string
+
+	// The edges between the nodes, so Edges[i] leads from Nodes[i]
+	// to Nodes[i+1].
+	Edges []Edge
+
+	// The sum of the weights of the edges.
+	Cost float64
+}
+
+// Len returns the number of edges of the path, its degrees of separation.
+func (p Path) Len() int {
+	return len(p.Edges)
+}
+
+// String makes the Path type implement the fmt.Stringer interface. It
+// returns the chain of names and reasons, such as
+// "Arya Stark → (sibling) → Jon Snow → (sworn to) → Night's Watch".
+func (p Path) String() string {
+	if len(p.Names) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(p.Names[0])
+	for i, edge := range p.Edges {
+		b.WriteString(" → (" + edge.Label + ") → " + p.Names[i+1])
+	}
+
+	return b.String()
+}
+
+// ShortestPath returns the path of the least cost between the nodes. Ties
+// are broken by the number of edges.
+func (g *Graph) ShortestPath(from, to Node, opts ...Option) (Path, error) {
+	o := &options{
+		weights: map[EdgeKind]float64{},
+		exclude: map[EdgeKind]bool{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	weights := map[EdgeKind]float64{}
+	for _, kind := range edgeKinds {
+		weights[kind] = 1
+		if weight, ok := o.weights[kind]; ok {
+			if weight < 0 {
+				return Path{}, ErrNegativeWeight
+			}
+			weights[kind] = weight
+		}
+	}
+
+	if !g.has(from) || !g.has(to) {
+		return Path{}, ErrUnknownNode
+	}
+
+	best := map[Node]item{from: {node: from}}
+	via := map[Node]Edge{}
+	done := map[Node]bool{}
+
+	queue := &queue{}
+	heap.Push(queue, best[from])
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(item)
+		if done[current.node] {
+			continue
+		}
+		done[current.node] = true
+
+		if current.node == to {
+			return g.path(from, to, via, current.cost), nil
+		}
+
+		for _, edge := range g.edges[current.node] {
+			if o.exclude[edge.Kind] || done[edge.To] {
+				continue
+			}
+
+			next := item{
+				node:  edge.To,
+				cost:  current.cost + weights[edge.Kind],
+				hops:  current.hops + 1,
+				order: queue.pushed,
+			}
+			if known, ok := best[edge.To]; ok && !next.before(known) {
+				continue
+			}
+
+			best[edge.To] = next
+			via[edge.To] = edge
+			heap.Push(queue, next)
+		}
+	}
+
+	return Path{}, ErrNoPath
+}
+
+// path follows the edges from the last node back to the first.
+func (g *Graph) path(from, to Node, via map[Node]Edge, cost float64) Path {
+	edges := []Edge{}
+	for node := to; node != from; node = via[node].From {
+		edges = append(edges, via[node])
+	}
+	for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+		edges[i], edges[j] = edges[j], edges[i]
+	}
+
+	p := Path{
+		Nodes: []Node{from},
+		Names: []string{g.Name(from)},
+		Edges: edges,
+		Cost:  cost,
+	}
+	for _, edge := range edges {
+		p.Nodes = append(p.Nodes, edge.To)
+		p.Names = append(p.Names, g.Name(edge.To))
+	}
+
+	return p
+}
+
+// item is a node reached by the search, with the cost of reaching it.
+type item struct {
+	node  Node
+	cost  float64
+	hops  int
+	order int
+}
+
+// before reports whether the item should be visited before the other, which
+// makes the search prefer cheap, then short, then early found paths.
+func (i item) before(other item) bool {
+	if i.cost != other.cost {
+		return i.cost < other.cost
+	}
+	if i.hops != other.hops {
+		return i.hops < other.hops
+	}
+
+	return i.order < other.order
+}
+
+// queue is a priority queue of items, implementing heap.Interface.
+type queue struct {
+	items  []item
+	pushed int
+}
+
+func (q *queue) Len() int           { return len(q.items) }
+func (q *queue) Less(i, j int) bool { return q.items[i].before(q.items[j]) }
+func (q *queue) Swap(i, j int)      { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *queue) Push(x interface{}) {
+	q.items = append(q.items, x.(item))
+	q.pushed++
+}
+
+func (q *queue) Pop() interface{} {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
@@ -0,0 +1,205 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package separation
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/internal/testfixture"
+)
+
+// testGraph returns the graph used by the tests:
+//
+//	Eddard (1) is the son of Rickard (50), who was not loaded.
+//	Arya (2) and Jon (3) are children of Eddard.
+//	Jon is loyal and sworn to the Night's Watch (10).
+//	Arya and Tyrion (5) appear in the book (100), Tyrion as POV.
+//	Hodor (6) is connected to no one.
+func testGraph() *Graph {
+	character := func(id int, name string, father *goiaf.CharacterID, allegiances ...goiaf.HouseID) goiaf.Character {
+		return goiaf.Character{
+			URL:           testfixture.CharacterURL(id),
+			Name:          name,
+			FatherID:      father,
+			AllegianceIds: allegiances,
+		}
+	}
+
+	return New(
+		[]goiaf.Book{{
+			URL:             testfixture.BookURL(100),
+			Name:            "A Game of Thrones",
+			CharacterIds:    []goiaf.CharacterID{2, 5},
+			PovCharacterIds: []goiaf.CharacterID{5},
+		}},
+		[]goiaf.Character{
+			character(1, "Eddard", testfixture.CharacterRef(50)),
+			character(2, "Arya", testfixture.CharacterRef(1)),
+			character(3, "Jon", testfixture.CharacterRef(1), 10),
+			character(5, "Tyrion", nil),
+			character(6, "Hodor", nil),
+			{URL: "", Name: "Character without id"},
+		},
+		[]goiaf.House{{
+			URL:             testfixture.HouseURL(10),
+			Name:            "Night's Watch",
+			SwornMembersIds: []goiaf.CharacterID{3},
+		}},
+	)
+}
+
+func TestShortestPath(t *testing.T) {
+	g := testGraph()
+
+	path, err := g.ShortestPath(CharacterNode(2), HouseNode(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Arya → (half-sibling) → Jon → (loyal to) → Night's Watch"; path.String() != want {
+		t.Errorf("String() = %q, want %q", path, want)
+	}
+	if path.Len() != 2 || path.Cost != 2 {
+		t.Errorf("Len() = %d, Cost = %v, want 2 and 2", path.Len(), path.Cost)
+	}
+	if want := []Node{CharacterNode(2), CharacterNode(3), HouseNode(10)}; !slices.Equal(path.Nodes, want) {
+		t.Errorf("Nodes = %v, want %v", path.Nodes, want)
+	}
+	for i, edge := range path.Edges {
+		if edge.From != path.Nodes[i] || edge.To != path.Nodes[i+1] {
+			t.Errorf("Edges[%d] = %+v, want it to lead from %v to %v", i, edge, path.Nodes[i], path.Nodes[i+1])
+		}
+	}
+}
+
+func TestShortestPathOptions(t *testing.T) {
+	g := testGraph()
+	arya, watch := CharacterNode(2), HouseNode(10)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+		cost float64
+	}{
+		{
+			"Without sibling",
+			[]Option{Without(EdgeSibling)},
+			"Arya → (child of) → Eddard → (parent of) → Jon → (loyal to) → Night's Watch",
+			3,
+		},
+		{
+			"Without allegiance",
+			[]Option{Without(EdgeAllegiance)},
+			"Arya → (half-sibling) → Jon → (sworn to) → Night's Watch",
+			2,
+		},
+		{
+			"expensive siblings",
+			[]Option{WithWeight(EdgeSibling, 5)},
+			"Arya → (child of) → Eddard → (parent of) → Jon → (loyal to) → Night's Watch",
+			3,
+		},
+		{
+			"free parents",
+			[]Option{WithWeight(EdgeParent, 0)},
+			"Arya → (child of) → Eddard → (parent of) → Jon → (loyal to) → Night's Watch",
+			1,
+		},
+		{
+			"equal cost prefers fewer edges",
+			[]Option{WithWeight(EdgeParent, 0.5)},
+			"Arya → (half-sibling) → Jon → (loyal to) → Night's Watch",
+			2,
+		},
+	}
+
+	for _, test := range tests {
+		path, err := g.ShortestPath(arya, watch, test.opts...)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if path.String() != test.want || path.Cost != test.cost {
+			t.Errorf("%s: path = %q with cost %v, want %q with cost %v", test.name, path, path.Cost, test.want, test.cost)
+		}
+	}
+}
+
+func TestShortestPathPOV(t *testing.T) {
+	g := testGraph()
+
+	path, err := g.ShortestPath(CharacterNode(5), BookNode(100), Without(EdgeAppearance))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Tyrion → (POV in) → A Game of Thrones"; path.String() != want {
+		t.Errorf("String() = %q, want %q", path, want)
+	}
+}
+
+func TestShortestPathUnloadedNode(t *testing.T) {
+	g := testGraph()
+
+	path, err := g.ShortestPath(CharacterNode(2), CharacterNode(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Arya → (child of) → Eddard → (child of) → Character 50"; path.String() != want {
+		t.Errorf("String() = %q, want %q", path, want)
+	}
+}
+
+func TestShortestPathSelf(t *testing.T) {
+	path, err := testGraph().ShortestPath(CharacterNode(2), CharacterNode(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path.Len() != 0 || path.String() != "Arya" {
+		t.Errorf("path = %q of length %d, want Arya of length 0", path, path.Len())
+	}
+}
+
+func TestShortestPathErrors(t *testing.T) {
+	g := testGraph()
+
+	tests := []struct {
+		name     string
+		from, to Node
+		opts     []Option
+		err      error
+	}{
+		{"unknown from", CharacterNode(999), CharacterNode(2), nil, ErrUnknownNode},
+		{"unknown to", CharacterNode(2), HouseNode(999), nil, ErrUnknownNode},
+		{"isolated", CharacterNode(2), CharacterNode(6), nil, ErrNoPath},
+		{"excluded", CharacterNode(2), HouseNode(10), []Option{Without(EdgeSibling, EdgeParent)}, ErrNoPath},
+		{"negative weight", CharacterNode(2), HouseNode(10), []Option{WithWeight(EdgeSpouse, -1)}, ErrNegativeWeight},
+	}
+
+	for _, test := range tests {
+		if _, err := g.ShortestPath(test.from, test.to, test.opts...); err != test.err {
+			t.Errorf("%s: ShortestPath returned %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestGraphNames(t *testing.T) {
+	g := testGraph()
+
+	tests := map[Node]string{
+		CharacterNode(2):  "Arya",
+		CharacterNode(50): "Character 50",
+		HouseNode(10):     "Night's Watch",
+		BookNode(100):     "A Game of Thrones",
+		BookNode(7):       "Book 7",
+	}
+	for node, want := range tests {
+		if got := g.Name(node); got != want {
+			t.Errorf("Name(%v) = %q, want %q", node, got, want)
+		}
+	}
+}
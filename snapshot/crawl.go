@@ -0,0 +1,330 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+const (
+	stateFile string = "state.json"
+)
+
+// crawlState is the progress of a crawl, stored in the work directory
+// so an interrupted crawl can be resumed.
+type crawlState struct {
+	CrawledAt time.Time                 `json:"crawledAt"`
+	BaseURL   string                    `json:"baseUrl"`
+	Resources map[string]*resourceState `json:"resources"`
+}
+
+// baseURLer is implemented by clients which know the base URL of the api
+// they send their requests to, such as the clients returned by goiaf.NewClient.
+type baseURLer interface {
+	BaseURL() string
+}
+
+type resourceState struct {
+	// The next page to fetch.
+	NextPage int `json:"nextPage"`
+
+	// The size of the records file after the last completed page. Records
+	// written after it belong to an unfinished page and are discarded.
+	Offset int64 `json:"offset"`
+
+	Count int  `json:"count"`
+	Done  bool `json:"done"`
+}
+
+// Crawl fetches every book, character and house through the client and writes
+// them to a snapshot archive at path, returning its manifest.
+//
+// While crawling, the progress is kept in the directory path + ".partial".
+// If the crawl is interrupted, e.g. by cancelling the context, calling Crawl
+// again with the same path resumes it from the last completed page. The
+// directory is removed once the archive has been written.
+//
+// Manifest.BaseURL is taken from the BaseURL method of the client, if it has
+// one, and is empty otherwise.
+func Crawl(ctx context.Context, client goiaf.Client, path string) (*Manifest, error) {
+	workDir := path + ".partial"
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(workDir)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := client.(baseURLer); ok && state.BaseURL == "" {
+		state.BaseURL = c.BaseURL()
+	}
+
+	err = crawlResource(ctx, workDir, state, Books, func(page int) ([]interface{}, bool, error) {
+		response, err := client.BooksContext(ctx, goiaf.NewBookRequest().Page(page).PageSize(goiaf.MaxPageSize))
+		if err != nil {
+			return nil, false, err
+		}
+		records := []interface{}{}
+		for _, book := range response.Data {
+			records = append(records, book)
+		}
+		next, err := hasNext(response.Next())
+		return records, next, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = crawlResource(ctx, workDir, state, Characters, func(page int) ([]interface{}, bool, error) {
+		response, err := client.CharactersContext(ctx, goiaf.NewCharacterRequest().Page(page).PageSize(goiaf.MaxPageSize))
+		if err != nil {
+			return nil, false, err
+		}
+		records := []interface{}{}
+		for _, character := range response.Data {
+			records = append(records, character)
+		}
+		next, err := hasNext(response.Next())
+		return records, next, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = crawlResource(ctx, workDir, state, Houses, func(page int) ([]interface{}, bool, error) {
+		response, err := client.HousesContext(ctx, goiaf.NewHouseRequest().Page(page).PageSize(goiaf.MaxPageSize))
+		if err != nil {
+			return nil, false, err
+		}
+		records := []interface{}{}
+		for _, house := range response.Data {
+			records = append(records, house)
+		}
+		next, err := hasNext(response.Next())
+		return records, next, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:   FormatVersion,
+		CrawledAt: state.CrawledAt,
+		BaseURL:   state.BaseURL,
+		Counts: map[string]int{
+			Books:      state.Resources[Books].Count,
+			Characters: state.Resources[Characters].Count,
+			Houses:     state.Resources[Houses].Count,
+		},
+	}
+
+	if err := writeArchive(path, workDir, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, os.RemoveAll(workDir)
+}
+
+// hasNext reports whether a response has a next result set. It takes the
+// return values of the Next method of a response.
+func hasNext(_ interface{}, err error) (bool, error) {
+	if err == goiaf.ErrNoResultSet {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// crawlResource fetches the pages of one resource type, starting at the next
+// page of its state, and appends the records to its file in the work directory.
+// fetch returns the records of a page and whether a next page exists.
+func crawlResource(ctx context.Context, workDir string, state *crawlState, name string, fetch func(page int) ([]interface{}, bool, error)) error {
+	rs := state.Resources[name]
+	if rs.Done {
+		return nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(workDir, name+".jsonl"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := f.Truncate(rs.Offset); err != nil {
+		return err
+	}
+	if _, err := f.Seek(rs.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	for !rs.Done {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		records, next, err := fetch(rs.NextPage)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(f)
+		for _, record := range records {
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+		if err := f.Sync(); err != nil {
+			return err
+		}
+
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+
+		rs.Offset = offset
+		rs.Count += len(records)
+		rs.NextPage++
+		rs.Done = !next
+		if err := state.save(workDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadState(workDir string) (*crawlState, error) {
+	state := &crawlState{
+		CrawledAt: time.Now().UTC(),
+		Resources: map[string]*resourceState{},
+	}
+
+	b, err := os.ReadFile(filepath.Join(workDir, stateFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, state); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range []string{Books, Characters, Houses} {
+		if state.Resources[name] == nil {
+			state.Resources[name] = &resourceState{NextPage: 1}
+		}
+	}
+
+	return state, nil
+}
+
+func (state *crawlState) save(workDir string) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(workDir, stateFile+".tmp")
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(workDir, stateFile))
+}
+
+// writeArchive writes the manifest and the compressed records files from
+// the work directory to a tar archive at path.
+func writeArchive(path, workDir string, manifest *Manifest) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	tw := tar.NewWriter(tmp)
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := writeTarFile(tw, manifestFile, b, manifest.CrawledAt); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	files := map[string]string{
+		Books:      booksFile,
+		Characters: charactersFile,
+		Houses:     housesFile,
+	}
+	for _, name := range []string{Books, Characters, Houses} {
+		b, err := gzipFile(filepath.Join(workDir, name+".jsonl"))
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		if err := writeTarFile(tw, files[name], b, manifest.CrawledAt); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func writeTarFile(tw *tar.Writer, name string, b []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(b)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err := tw.Write(b)
+	return err
+}
+
+func gzipFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	if _, err := io.Copy(zw, f); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
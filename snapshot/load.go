@@ -0,0 +1,121 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+var (
+	// ErrManifestMissing will be used if an archive has no manifest.
+	ErrManifestMissing = errors.New("Manifest missing from snapshot archive")
+
+	// ErrUnsupportedVersion will be used if an archive was written by a
+	// newer version of this package, or has no valid version.
+	ErrUnsupportedVersion = errors.New("Unsupported snapshot archive version")
+)
+
+// Load reads the snapshot archive at path.
+func Load(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Read reads a snapshot archive from r.
+func Read(r io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	hasManifest := false
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch header.Name {
+		case manifestFile:
+			if err := json.NewDecoder(tr).Decode(&snapshot.Manifest); err != nil {
+				return nil, err
+			}
+			if snapshot.Manifest.Version < 1 || snapshot.Manifest.Version > FormatVersion {
+				return nil, ErrUnsupportedVersion
+			}
+			hasManifest = true
+		case booksFile:
+			err = readRecords(tr, func(dec *json.Decoder) error {
+				var book goiaf.Book
+				if err := dec.Decode(&book); err != nil {
+					return err
+				}
+				snapshot.Books = append(snapshot.Books, book)
+				return nil
+			})
+		case charactersFile:
+			err = readRecords(tr, func(dec *json.Decoder) error {
+				var character goiaf.Character
+				if err := dec.Decode(&character); err != nil {
+					return err
+				}
+				snapshot.Characters = append(snapshot.Characters, character)
+				return nil
+			})
+		case housesFile:
+			err = readRecords(tr, func(dec *json.Decoder) error {
+				var house goiaf.House
+				if err := dec.Decode(&house); err != nil {
+					return err
+				}
+				snapshot.Houses = append(snapshot.Houses, house)
+				return nil
+			})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", header.Name, err)
+		}
+	}
+
+	if !hasManifest {
+		return nil, ErrManifestMissing
+	}
+
+	return snapshot, nil
+}
+
+// readRecords decompresses a JSON Lines file and calls decode until all
+// records have been read.
+func readRecords(r io.Reader, decode func(*json.Decoder) error) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(zr))
+	for dec.More() {
+		if err := decode(dec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package snapshot dumps all books, characters and houses of An API Of Ice And Fire
+into a single versioned archive, and loads them back, so the data can be used
+without depending on the live api.
+
+	manifest, err := snapshot.Crawl(ctx, goiaf.NewClient(), "iaf.snapshot")
+	checkErr(err)
+
+	s, err := snapshot.Load("iaf.snapshot")
+	checkErr(err)
+	fmt.Println(len(s.Characters))
+
+The archive is a tar file holding a manifest.json and one gzip compressed JSON
+Lines file per resource type: books.jsonl.gz, characters.jsonl.gz and
+houses.jsonl.gz.
+*/
+package snapshot
+
+import (
+	"time"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+const (
+	// FormatVersion is the version of the archive format written by Crawl.
+	// Load accepts archives up to this version.
+	FormatVersion int = 1
+
+	manifestFile   string = "manifest.json"
+	booksFile      string = "books.jsonl.gz"
+	charactersFile string = "characters.jsonl.gz"
+	housesFile     string = "houses.jsonl.gz"
+)
+
+// Resource types, used as keys of Manifest.Counts.
+const (
+	Books      string = "books"
+	Characters string = "characters"
+	Houses     string = "houses"
+)
+
+// Manifest describes the content of a snapshot archive.
+type Manifest struct {
+	// The version of the archive format.
+	Version int `json:"version"`
+
+	// The time the crawl was started.
+	CrawledAt time.Time `json:"crawledAt"`
+
+	// The base URL of the api the snapshot was crawled from.
+	BaseURL string `json:"baseUrl"`
+
+	// The number of records per resource type.
+	Counts map[string]int `json:"counts"`
+}
+
+// Snapshot is the content of a snapshot archive.
+type Snapshot struct {
+	Manifest Manifest
+
+	Books      []goiaf.Book
+	Characters []goiaf.Character
+	Houses     []goiaf.House
+}
+
+// Client returns a goiaf.Client which serves the content of the snapshot
+// from memory, without any network requests.
+func (s *Snapshot) Client() goiaf.Client {
+	return goiaf.NewOfflineClient(s.Books, s.Characters, s.Houses)
+}
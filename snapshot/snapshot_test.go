@@ -0,0 +1,274 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattiaspernhult/goiaf"
+)
+
+// fakeAPI serves numbered books, characters and houses in the format of
+// the api, and fails the requests for which fail returns true.
+type fakeAPI struct {
+	*httptest.Server
+
+	counts map[string]int
+
+	mu       sync.Mutex
+	requests map[string]int
+	fail     func(name string, page int) bool
+}
+
+func newFakeAPI(t *testing.T, books, characters, houses int) *fakeAPI {
+	api := &fakeAPI{
+		counts:   map[string]int{Books: books, Characters: characters, Houses: houses},
+		requests: map[string]int{},
+	}
+	api.Server = httptest.NewServer(http.HandlerFunc(api.serveHTTP))
+	t.Cleanup(api.Close)
+
+	return api
+}
+
+func (api *fakeAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/")
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+
+	api.mu.Lock()
+	api.requests[name]++
+	fail := api.fail != nil && api.fail(name, page)
+	api.mu.Unlock()
+
+	if fail {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	total := api.counts[name]
+	last := (total + pageSize - 1) / pageSize
+	if last < 1 {
+		last = 1
+	}
+	link := func(rel string, p int) string {
+		return fmt.Sprintf(`<%s/api/%s?page=%d&pageSize=%d>; rel="%s"`, api.URL, name, p, pageSize, rel)
+	}
+	links := []string{}
+	if page < last {
+		links = append(links, link("next", page+1))
+	}
+	links = append(links, link("first", 1), link("last", last))
+	w.Header().Set("Link", strings.Join(links, ", "))
+
+	records := []map[string]string{}
+	for id := (page-1)*pageSize + 1; id <= page*pageSize && id <= total; id++ {
+		records = append(records, map[string]string{
+			"url":  fmt.Sprintf("%s/api/%s/%d", api.URL, name, id),
+			"name": fmt.Sprintf("%s %d", name, id),
+		})
+	}
+	json.NewEncoder(w).Encode(records)
+}
+
+func (api *fakeAPI) requestCount(name string) int {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	return api.requests[name]
+}
+
+func (api *fakeAPI) client() goiaf.Client {
+	return goiaf.NewClient(
+		goiaf.WithBaseURL(api.URL+"/api"),
+		goiaf.WithRetryPolicy(goiaf.RetryPolicy{MaxAttempts: 1}),
+	)
+}
+
+func TestCrawlAndLoad(t *testing.T) {
+	api := newFakeAPI(t, 3, 120, 2)
+	path := filepath.Join(t.TempDir(), "iaf.snapshot")
+
+	manifest, err := Crawl(context.Background(), api.client(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Version != FormatVersion || manifest.BaseURL != api.URL+"/api" {
+		t.Errorf("manifest = %+v, want version %d and base URL %s/api", manifest, FormatVersion, api.URL)
+	}
+	want := map[string]int{Books: 3, Characters: 120, Houses: 2}
+	for name, count := range want {
+		if manifest.Counts[name] != count {
+			t.Errorf("Counts[%s] = %d, want %d", name, manifest.Counts[name], count)
+		}
+	}
+	if _, err := os.Stat(path + ".partial"); !os.IsNotExist(err) {
+		t.Errorf("work directory was not removed: %v", err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Books) != 3 || len(s.Characters) != 120 || len(s.Houses) != 2 {
+		t.Fatalf("loaded %d books, %d characters and %d houses, want 3, 120 and 2", len(s.Books), len(s.Characters), len(s.Houses))
+	}
+	for i, character := range s.Characters {
+		if want := fmt.Sprintf("characters %d", i+1); character.Name != want {
+			t.Fatalf("Characters[%d].Name = %q, want %q", i, character.Name, want)
+		}
+	}
+	if s.Manifest.BaseURL != manifest.BaseURL {
+		t.Errorf("loaded manifest has base URL %q, want %q", s.Manifest.BaseURL, manifest.BaseURL)
+	}
+}
+
+func TestCrawlEmpty(t *testing.T) {
+	api := newFakeAPI(t, 0, 0, 0)
+	path := filepath.Join(t.TempDir(), "iaf.snapshot")
+
+	manifest, err := Crawl(context.Background(), api.client(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The base URL comes from the client, not from the crawled resources.
+	if manifest.BaseURL != api.URL+"/api" {
+		t.Errorf("BaseURL = %q, want %s/api", manifest.BaseURL, api.URL)
+	}
+}
+
+func TestCrawlResume(t *testing.T) {
+	api := newFakeAPI(t, 3, 120, 2)
+	api.fail = func(name string, page int) bool {
+		return name == Characters && page == 2
+	}
+	path := filepath.Join(t.TempDir(), "iaf.snapshot")
+
+	if _, err := Crawl(context.Background(), api.client(), path); err == nil {
+		t.Fatal("Crawl succeeded although a page failed")
+	}
+
+	workDir := path + ".partial"
+	state, err := loadState(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	characters := state.Resources[Characters]
+	if !state.Resources[Books].Done || characters.Done || characters.NextPage != 2 || characters.Count != 50 {
+		t.Fatalf("state after the interruption = books %+v, characters %+v, want books done and characters at page 2",
+			state.Resources[Books], characters)
+	}
+
+	// Records of a page which was written only partly before the crawl was
+	// interrupted are discarded when it is resumed.
+	f, err := os.OpenFile(filepath.Join(workDir, Characters+".jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(f, "{\"url\":\"%s/api/characters/51\",\"name\":\"characters 51\"}\n{\"url\":", api.URL)
+	f.Close()
+
+	api.fail = nil
+	manifest, err := Crawl(context.Background(), api.client(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Counts[Characters] != 120 {
+		t.Errorf("Counts[characters] = %d, want 120", manifest.Counts[Characters])
+	}
+	if !manifest.CrawledAt.Equal(state.CrawledAt) {
+		t.Errorf("CrawledAt = %v, want the start of the interrupted crawl %v", manifest.CrawledAt, state.CrawledAt)
+	}
+
+	// The books were complete and are not fetched again, the characters
+	// continue at the failed page.
+	if api.requestCount(Books) != 1 || api.requestCount(Characters) != 4 {
+		t.Errorf("the api received %d requests for books and %d for characters, want 1 and 4",
+			api.requestCount(Books), api.requestCount(Characters))
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Characters) != 120 {
+		t.Fatalf("loaded %d characters, want 120", len(s.Characters))
+	}
+	for i, character := range s.Characters {
+		if want := fmt.Sprintf("characters %d", i+1); character.Name != want {
+			t.Fatalf("Characters[%d].Name = %q, want %q", i, character.Name, want)
+		}
+	}
+}
+
+func TestCrawlCancelled(t *testing.T) {
+	api := newFakeAPI(t, 3, 120, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	path := filepath.Join(t.TempDir(), "iaf.snapshot")
+	if _, err := Crawl(ctx, api.client(), path); err != context.Canceled {
+		t.Errorf("Crawl returned %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("archive was written for a cancelled crawl: %v", err)
+	}
+}
+
+// archive returns a tar archive holding the files.
+func archive(t *testing.T, files map[string][]byte) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for name, b := range files {
+		if err := writeTarFile(tw, name, b, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf
+}
+
+func TestReadVersions(t *testing.T) {
+	tests := []struct {
+		manifest string
+		err      error
+	}{
+		{fmt.Sprintf(`{"version":%d}`, FormatVersion), nil},
+		{fmt.Sprintf(`{"version":%d}`, FormatVersion+1), ErrUnsupportedVersion},
+		{`{"version":0}`, ErrUnsupportedVersion},
+		{`{}`, ErrUnsupportedVersion},
+	}
+
+	for _, test := range tests {
+		_, err := Read(archive(t, map[string][]byte{manifestFile: []byte(test.manifest)}))
+		if err != test.err {
+			t.Errorf("Read of manifest %s returned %v, want %v", test.manifest, err, test.err)
+		}
+	}
+
+	if _, err := Read(archive(t, map[string][]byte{})); err != ErrManifestMissing {
+		t.Errorf("Read without manifest returned %v, want ErrManifestMissing", err)
+	}
+}
@@ -5,30 +5,70 @@
 package goiaf
 
 import (
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 )
 
+// urlString is the URL of a resource as returned by the api, e.g.
+// https://anapioficeandfire.com/api/characters/583. An empty urlString
+// means that there is no such resource.
 type urlString string
 
-func (us urlString) id() int {
-	strValue := string(us)
-	if strValue == "" {
-		return -1
+// UnmarshalJSON makes the urlString type implement the json.Unmarshaler
+// interface. It fails if the URL does not end with the id of a resource.
+func (us *urlString) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if s != "" {
+		if _, err := parseID(s); err != nil {
+			return err
+		}
 	}
 
-	idStr := strValue[strings.LastIndex(strValue, "/")+1 : len(strValue)]
+	*us = urlString(s)
+	return nil
+}
 
-	id, _ := strconv.Atoi(idStr)
+// id returns the id at the end of the URL, or 0 if the URL is empty or invalid.
+func (us urlString) id() int {
+	id, _ := parseID(string(us))
 	return id
 }
 
+func parseID(urlStr string) (int, error) {
+	idStr := urlStr[strings.LastIndex(urlStr, "/")+1:]
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("Invalid resource URL %q", urlStr)
+	}
+
+	return id, nil
+}
+
 type urlStringSlice []urlString
 
-func (uss urlStringSlice) ids() []int {
-	ids := []int{}
+// optionalID returns the id of the resource, or nil if there is no resource.
+func optionalID[T ~int](us urlString) *T {
+	if us == "" {
+		return nil
+	}
+
+	id := T(us.id())
+	return &id
+}
+
+func ids[T ~int](uss urlStringSlice) []T {
+	ids := []T{}
 	for _, us := range uss {
-		ids = append(ids, us.id())
+		if us != "" {
+			ids = append(ids, T(us.id()))
+		}
 	}
 
 	return ids
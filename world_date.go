@@ -0,0 +1,242 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Era is the era of a year in the calendar of Westeros, counted from
+// Aegon's Conquest.
+type Era int
+
+const (
+	// EraUnknown is used if a date has no year, or a year without era.
+	EraUnknown Era = iota
+
+	// EraAC is used for years After the Conquest.
+	EraAC
+
+	// EraBC is used for years Before the Conquest.
+	EraBC
+)
+
+// String makes the Era type implement the fmt.Stringer interface.
+func (e Era) String() string {
+	switch e {
+	case EraAC:
+		return "AC"
+	case EraBC:
+		return "BC"
+	}
+
+	return ""
+}
+
+// WorldDate is a date in the world of Ice And Fire, parsed from the free
+// text the api uses for births, deaths and the founding of houses, e.g.
+// "In 283 AC", "In or around 250 AC, at Riverrun" or "Before the Age of Heroes".
+//
+// Years are only known for some dates. Absolute years, as used by
+// Start, End, Character.AgeAt and Character.AliveIn, count years after the
+// Conquest as positive and years before the Conquest as negative.
+type WorldDate struct {
+	// The original text.
+	Text string
+
+	// The era of Year and ToYear.
+	Era Era
+
+	// The year of the date, as written in the text. Only set if HasYear is true.
+	Year int
+
+	// The last year of a range, e.g. 285 in "Between 283 AC and 285 AC".
+	// Equal to Year if the date is not a range.
+	ToYear int
+
+	// HasYear is set if the text contains a year.
+	HasYear bool
+
+	// Approximate is set if the year is not exact, e.g. "In or around 250 AC".
+	Approximate bool
+
+	// Range is set if the date is one of several years, e.g. "In 264 AC or 265 AC".
+	Range bool
+
+	// Before is set if the date is at or before the year, e.g. "In or before 240 AC".
+	Before bool
+
+	// After is set if the date is at or after the year, e.g. "In or after 280 AC".
+	After bool
+
+	// The place of the date, e.g. "Riverrun" in "In 299 AC, at Riverrun".
+	Place string
+}
+
+var (
+	worldYearPattern = regexp.MustCompile(`(?i)\b(\d{1,4})\s*(AC|BC)?\b`)
+
+	// A place is introduced by a preposition at the start of the text, or
+	// after a comma, and is capitalized, e.g. "In 299 AC, at Riverrun".
+	// "In" only introduces a place after a comma, as "In the Dawn Age" is
+	// a time rather than a place.
+	worldPlacePattern = regexp.MustCompile(`(?:^(?i:at|near|on)|,\s*(?i:at|in|near|on)|\s(?i:at|near|on))\s+((?i:the\s+)?[A-Z][^,]*)$`)
+
+	worldApproxPattern = regexp.MustCompile(`(?i)\b(?:around|about|circa|approximately|roughly)\b|\b(?:c|ca)\.`)
+)
+
+// ParseWorldDate parses the text of a date. Parts of the text which are not
+// understood are ignored, the original text is always kept in Text.
+func ParseWorldDate(text string) WorldDate {
+	date := WorldDate{Text: text}
+	rest := strings.TrimSpace(text)
+	if rest == "" {
+		return date
+	}
+
+	if m := worldPlacePattern.FindStringSubmatchIndex(rest); m != nil {
+		place := strings.TrimRight(strings.TrimSpace(rest[m[2]:m[3]]), ".;:!?")
+		// A place never starts with a year or an era, e.g. "In 283 AC".
+		if !worldYearPattern.MatchString(place) {
+			date.Place = place
+			rest = strings.TrimSpace(rest[:m[0]])
+		}
+	}
+
+	lower := strings.ToLower(rest)
+	date.Approximate = worldApproxPattern.MatchString(rest)
+	date.Before = strings.HasPrefix(lower, "before") || strings.Contains(lower, "or before")
+	date.After = strings.HasPrefix(lower, "after") || strings.Contains(lower, "or after")
+
+	for _, m := range worldYearPattern.FindAllStringSubmatch(rest, -1) {
+		year, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+
+		era := EraUnknown
+		switch strings.ToUpper(m[2]) {
+		case "AC":
+			era = EraAC
+		case "BC":
+			era = EraBC
+		}
+
+		if !date.HasYear {
+			date.HasYear = true
+			date.Year, date.ToYear, date.Era = year, year, era
+			continue
+		}
+
+		date.Range = true
+		if date.Era == EraUnknown {
+			date.Era = era
+		}
+		if absoluteYear(year, date.Era) < absoluteYear(date.Year, date.Era) {
+			date.Year = year
+		}
+		if absoluteYear(year, date.Era) > absoluteYear(date.ToYear, date.Era) {
+			date.ToYear = year
+		}
+	}
+
+	return date
+}
+
+// absoluteYear converts a year of the given era to an absolute year. Years
+// of an unknown era are treated as years after the Conquest.
+func absoluteYear(year int, era Era) int {
+	if era == EraBC {
+		return -year
+	}
+
+	return year
+}
+
+// Start returns the first absolute year of the date, and whether the date
+// has a year.
+func (d WorldDate) Start() (int, bool) {
+	return absoluteYear(d.Year, d.Era), d.HasYear
+}
+
+// End returns the last absolute year of the date, and whether the date
+// has a year.
+func (d WorldDate) End() (int, bool) {
+	return absoluteYear(d.ToYear, d.Era), d.HasYear
+}
+
+// String makes the WorldDate type implement the fmt.Stringer interface.
+// It returns the original text.
+func (d WorldDate) String() string {
+	return d.Text
+}
+
+// BornDate returns the parsed date of birth of the character.
+func (c Character) BornDate() WorldDate {
+	return ParseWorldDate(c.Born)
+}
+
+// DiedDate returns the parsed date of death of the character.
+func (c Character) DiedDate() WorldDate {
+	return ParseWorldDate(c.Died)
+}
+
+// AgeAt returns the age of the character in the given absolute year, and
+// whether the age is known. If the year of birth is a range, the age is
+// counted from its first year.
+func (c Character) AgeAt(year int) (int, bool) {
+	born, ok := c.BornDate().Start()
+	if !ok || year < born {
+		return 0, false
+	}
+
+	return year - born, true
+}
+
+// AliveIn reports whether the character was alive in the given absolute
+// year, and whether that is known. A character is alive in the year of
+// its death. A character without a date of death is assumed to be alive.
+func (c Character) AliveIn(year int) (bool, bool) {
+	born, ok := c.BornDate().End()
+	if !ok {
+		return false, false
+	}
+	if year < born {
+		start, _ := c.BornDate().Start()
+		return false, year < start
+	}
+
+	if c.Died == "" {
+		return true, true
+	}
+
+	diedDate := c.DiedDate()
+	diedStart, ok := diedDate.Start()
+	if !ok {
+		return false, false
+	}
+	diedEnd, _ := diedDate.End()
+
+	if year <= diedStart {
+		return true, true
+	}
+	if year > diedEnd {
+		return false, true
+	}
+
+	return false, false
+}
+
+// FoundedDate returns the parsed date the house was founded.
+func (h House) FoundedDate() WorldDate {
+	return ParseWorldDate(h.Founded)
+}
+
+// DiedOutDate returns the parsed date the house died out.
+func (h House) DiedOutDate() WorldDate {
+	return ParseWorldDate(h.DiedOut)
+}
@@ -0,0 +1,209 @@
+// Copyright 2017 Mattias Pernhult. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goiaf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/mattiaspernhult/goiaf"
+	"github.com/mattiaspernhult/goiaf/snapshot"
+)
+
+const (
+	worldDatesFile            = "testdata/world_dates.json"
+	unparseableWorldDatesFile = "testdata/world_dates_unparseable.json"
+)
+
+var (
+	snapshotPath = flag.String("snapshot", "testdata/iaf.snapshot", "snapshot archive whose dates are checked by TestParseWorldDateSnapshot")
+	updateDates  = flag.Bool("update-dates", false, "add the dates of the snapshot to "+worldDatesFile+" and "+unparseableWorldDatesFile)
+)
+
+// worldDateCase is an entry of testdata/world_dates.json.
+type worldDateCase struct {
+	Text        string `json:"text"`
+	Era         string `json:"era"`
+	Year        int    `json:"year,omitempty"`
+	ToYear      int    `json:"toYear,omitempty"`
+	Approximate bool   `json:"approximate,omitempty"`
+	Range       bool   `json:"range,omitempty"`
+	Before      bool   `json:"before,omitempty"`
+	After       bool   `json:"after,omitempty"`
+	Place       string `json:"place,omitempty"`
+}
+
+func newWorldDateCase(date goiaf.WorldDate) worldDateCase {
+	return worldDateCase{
+		Text:        date.Text,
+		Era:         date.Era.String(),
+		Year:        date.Year,
+		ToYear:      date.ToYear,
+		Approximate: date.Approximate,
+		Range:       date.Range,
+		Before:      date.Before,
+		After:       date.After,
+		Place:       date.Place,
+	}
+}
+
+func readJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+}
+
+// writeJSONLines writes the values as a JSON array with one value per line,
+// so the test data gives readable diffs.
+func writeJSONLines[T any](t *testing.T, path string, values []T) {
+	t.Helper()
+
+	var b bytes.Buffer
+	b.WriteString("[\n")
+	for i, value := range values {
+		line, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.WriteString("  ")
+		b.Write(line)
+		if i < len(values)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseWorldDateCorpus(t *testing.T) {
+	var cases []worldDateCase
+	readJSON(t, worldDatesFile, &cases)
+
+	for _, want := range cases {
+		date := goiaf.ParseWorldDate(want.Text)
+
+		if got := newWorldDateCase(date); got != want {
+			t.Errorf("ParseWorldDate(%q) = %+v, want %+v", want.Text, got, want)
+		}
+		if date.HasYear != (want.Year != 0) {
+			t.Errorf("ParseWorldDate(%q).HasYear = %v, want %v", want.Text, date.HasYear, want.Year != 0)
+		}
+	}
+
+	var unparseable []string
+	readJSON(t, unparseableWorldDatesFile, &unparseable)
+
+	for _, text := range unparseable {
+		if date := goiaf.ParseWorldDate(text); date.HasYear {
+			t.Errorf("%q has year %d, but is listed as unparseable", text, date.Year)
+		}
+	}
+}
+
+// TestParseWorldDateSnapshot checks the Born, Died, Founded and DiedOut
+// values of a real snapshot, which is not part of the repository:
+//
+//	go test -run TestParseWorldDateSnapshot -snapshot iaf.snapshot
+//
+// Every value must have a year, or be listed in
+// testdata/world_dates_unparseable.json. With -update-dates, values which
+// are not in the test data yet are added to it, to be reviewed.
+func TestParseWorldDateSnapshot(t *testing.T) {
+	s, err := snapshot.Load(*snapshotPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skipf("no snapshot at %s, create one with snapshot.Crawl", *snapshotPath)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []string{}
+	for _, character := range s.Characters {
+		texts = append(texts, character.Born, character.Died)
+	}
+	for _, house := range s.Houses {
+		texts = append(texts, house.Founded, house.DiedOut)
+	}
+	slices.Sort(texts)
+	texts = slices.Compact(texts)
+
+	var cases []worldDateCase
+	readJSON(t, worldDatesFile, &cases)
+	var unparseable []string
+	readJSON(t, unparseableWorldDatesFile, &unparseable)
+
+	known := map[string]bool{}
+	for _, c := range cases {
+		known[c.Text] = true
+	}
+
+	for _, text := range texts {
+		date := goiaf.ParseWorldDate(text)
+		listed := slices.Contains(unparseable, text)
+
+		switch {
+		case text == "":
+		case date.HasYear && listed:
+			t.Errorf("%q has year %d, but is listed as unparseable", text, date.Year)
+		case !date.HasYear && !listed && *updateDates:
+			unparseable = append(unparseable, text)
+		case !date.HasYear && !listed:
+			t.Errorf("%q has no year and is not listed as unparseable", text)
+		case date.HasYear && !known[text] && *updateDates:
+			cases = append(cases, newWorldDateCase(date))
+		}
+	}
+
+	if *updateDates {
+		slices.Sort(unparseable)
+		writeJSONLines(t, worldDatesFile, cases)
+		writeJSONLines(t, unparseableWorldDatesFile, unparseable)
+	}
+	t.Logf("checked %d distinct dates of %s", len(texts), *snapshotPath)
+}
+
+func TestCharacterAgeAndAlive(t *testing.T) {
+	c := goiaf.Character{Born: "In 283 AC", Died: "In 298 AC or 299 AC"}
+
+	if age, ok := c.AgeAt(290); !ok || age != 7 {
+		t.Errorf("AgeAt(290) = %d, %v, want 7, true", age, ok)
+	}
+	if _, ok := c.AgeAt(280); ok {
+		t.Error("AgeAt(280) is known, want unknown before birth")
+	}
+
+	tests := []struct {
+		year      int
+		alive, ok bool
+	}{
+		{280, false, true},
+		{283, true, true},
+		{298, true, true},
+		{299, false, false},
+		{300, false, true},
+	}
+	for _, test := range tests {
+		alive, ok := c.AliveIn(test.year)
+		if alive != test.alive || ok != test.ok {
+			t.Errorf("AliveIn(%d) = %v, %v, want %v, %v", test.year, alive, ok, test.alive, test.ok)
+		}
+	}
+}